@@ -0,0 +1,10 @@
+-- =====================================================
+-- Course equipment requirements
+-- Rooms assigned to a course must provide every flagged item
+-- =====================================================
+
+ALTER TABLE courses
+    ADD COLUMN IF NOT EXISTS requires_projector BOOLEAN DEFAULT false,
+    ADD COLUMN IF NOT EXISTS requires_computer BOOLEAN DEFAULT false,
+    ADD COLUMN IF NOT EXISTS requires_smart_board BOOLEAN DEFAULT false,
+    ADD COLUMN IF NOT EXISTS requires_ac BOOLEAN DEFAULT false;
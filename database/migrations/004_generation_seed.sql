@@ -0,0 +1,7 @@
+-- =====================================================
+-- Generation seed
+-- Re-running generation with the same seed and data reproduces the timetable
+-- =====================================================
+
+ALTER TABLE timetable_templates
+    ADD COLUMN IF NOT EXISTS generation_seed BIGINT;
@@ -0,0 +1,29 @@
+-- =====================================================
+-- Generation runs
+-- One row per timetable generation, with its settings and search metrics
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS generation_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    timetable_id UUID NOT NULL REFERENCES timetable_templates(id) ON DELETE CASCADE,
+    job_id UUID NOT NULL,
+    status VARCHAR(20) DEFAULT 'RUNNING' CHECK (status IN ('RUNNING', 'COMPLETED', 'FAILED', 'CANCELLED')),
+    error TEXT,
+    algorithm VARCHAR(50) NOT NULL,
+    config JSONB, -- Engine settings and the timetable constraints in force
+    seed BIGINT NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    duration_ms BIGINT DEFAULT 0,
+    iterations BIGINT DEFAULT 0,
+    best_fitness DOUBLE PRECISION,
+    hard_violations INTEGER DEFAULT 0,
+    soft_violations INTEGER DEFAULT 0,
+    classes_scheduled INTEGER DEFAULT 0,
+    fitness_history JSONB, -- Best and current fitness sampled over the run
+    penalty_breakdown JSONB, -- Penalty and fitness cost of each constraint
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_generation_runs_timetable ON generation_runs(timetable_id, started_at DESC);
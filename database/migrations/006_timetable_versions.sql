@@ -0,0 +1,60 @@
+-- =====================================================
+-- Timetable versions
+-- Every publish stores an immutable snapshot of the schedule. A version is in
+-- force from published_at until superseded_at.
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS timetable_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    timetable_id UUID NOT NULL REFERENCES timetable_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL CHECK (version > 0),
+    name VARCHAR(200) NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    published_by UUID,
+    superseded_at TIMESTAMP WITH TIME ZONE,
+    class_count INTEGER DEFAULT 0,
+    classes JSONB NOT NULL, -- Scheduled classes with course, faculty and room names
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT idx_timetable_versions_number UNIQUE (timetable_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_timetable_versions_in_force ON timetable_versions(timetable_id, published_at, superseded_at);
+
+-- Timetables published before versioning keep their current schedule as
+-- version 1, so they stay in force for students and for conflict checks
+INSERT INTO timetable_versions (timetable_id, version, name, published_at, class_count, classes)
+SELECT
+    t.id,
+    1,
+    t.name,
+    COALESCE(t.published_at, t.updated_at, NOW()),
+    COUNT(sc.id),
+    COALESCE(
+        jsonb_agg(jsonb_build_object(
+            'course_id', sc.course_id,
+            'course_code', c.code,
+            'course_name', c.name,
+            'faculty_id', sc.faculty_id,
+            'faculty_name', f.first_name || ' ' || f.last_name,
+            'room_id', sc.room_id,
+            'room_number', r.room_number,
+            'time_slot_id', sc.time_slot_id,
+            'day_of_week', sc.day_of_week,
+            'start_time', to_char(sc.start_time, 'HH24:MI:SS'),
+            'end_time', to_char(sc.end_time, 'HH24:MI:SS'),
+            'semester_id', sc.semester_id,
+            'is_lab', sc.is_lab,
+            'is_tutorial', sc.is_tutorial,
+            'batch_number', sc.batch_number
+        ) ORDER BY sc.day_of_week, sc.start_time) FILTER (WHERE sc.id IS NOT NULL),
+        '[]'::jsonb
+    )
+FROM timetable_templates t
+LEFT JOIN scheduled_classes sc ON sc.timetable_id = t.id
+LEFT JOIN courses c ON c.id = sc.course_id
+LEFT JOIN faculty f ON f.id = sc.faculty_id
+LEFT JOIN rooms r ON r.id = sc.room_id
+WHERE t.status = 'PUBLISHED'
+  AND NOT EXISTS (SELECT 1 FROM timetable_versions v WHERE v.timetable_id = t.id)
+GROUP BY t.id;
@@ -0,0 +1,7 @@
+-- =====================================================
+-- Disabled constraints
+-- Default constraints apply to every timetable unless a row switches them off
+-- =====================================================
+
+ALTER TABLE timetable_constraints
+    ADD COLUMN IF NOT EXISTS is_disabled BOOLEAN DEFAULT false;
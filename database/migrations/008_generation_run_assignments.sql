@@ -0,0 +1,7 @@
+-- =====================================================
+-- Generation run schedules
+-- Each run keeps the schedule it found so it can be promoted later
+-- =====================================================
+
+ALTER TABLE generation_runs
+    ADD COLUMN IF NOT EXISTS assignments JSONB; -- Meetings of the best solution found
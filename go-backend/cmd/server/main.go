@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -16,6 +19,7 @@ import (
 	"github.com/yourusername/timetable-scheduler/internal/config"
 	"github.com/yourusername/timetable-scheduler/internal/database"
 	"github.com/yourusername/timetable-scheduler/internal/handlers"
+	"github.com/yourusername/timetable-scheduler/internal/middleware"
 )
 
 func main() {
@@ -33,6 +37,11 @@ func main() {
 	}
 	defer database.Close()
 
+	// Timetables left GENERATING by a previous run will never finish
+	if err := handlers.RecoverInterruptedGenerations(); err != nil {
+		log.Printf("Failed to reset interrupted generations: %v", err)
+	}
+
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:      cfg.AppName,
@@ -45,6 +54,10 @@ func main() {
 		Format: "[${time}] ${status} - ${method} ${path} (${latency})\n",
 	}))
 	app.Use(compress.New(compress.Config{
+		// Compressing an event stream would buffer the events
+		Next: func(c *fiber.Ctx) bool {
+			return strings.HasSuffix(c.Path(), "/generate/stream")
+		},
 		Level: compress.LevelBestSpeed,
 	}))
 
@@ -66,7 +79,7 @@ func main() {
 	})
 
 	// API v1 routes
-	api := app.Group("/api/v1")
+	api := app.Group("/api/v1", apiMiddleware(cfg)...)
 
 	// Initialize handlers
 	handlers.SetupRoutes(api, cfg)
@@ -78,6 +91,11 @@ func main() {
 		<-sigChan
 
 		log.Println("Shutting down server...")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		handlers.ShutdownGenerations(ctx)
+		cancel()
+
 		_ = app.Shutdown()
 	}()
 
@@ -91,6 +109,46 @@ func main() {
 	}
 }
 
+// apiMiddleware guards the API v1 routes. Requests are limited per client IP
+// before the token is checked, then per user. The endpoints that run the
+// scheduler or conflict checks have a smaller budget of their own and do not
+// count against the general one.
+func apiMiddleware(cfg *config.Config) []fiber.Handler {
+	rateWindow := time.Duration(cfg.RateLimitDuration) * time.Second
+	return []fiber.Handler{
+		middleware.RateLimit(middleware.RateLimitConfig{
+			Max:    cfg.RateLimitIPRequests,
+			Window: rateWindow,
+			Key:    middleware.ByIP,
+		}),
+		middleware.Authenticate(cfg.JWTSecret),
+		middleware.RateLimit(middleware.RateLimitConfig{
+			Max:    cfg.RateLimitRequests,
+			Window: rateWindow,
+			Key:    middleware.ByUser,
+			Next:   isExpensiveRequest,
+		}),
+		middleware.RateLimit(middleware.RateLimitConfig{
+			Max:    cfg.RateLimitExpensiveRequests,
+			Window: rateWindow,
+			Key:    middleware.ByUser,
+			Next: func(c *fiber.Ctx) bool {
+				return !isExpensiveRequest(c)
+			},
+		}),
+	}
+}
+
+// isExpensiveRequest reports whether the request starts a timetable
+// generation or a conflict check
+func isExpensiveRequest(c *fiber.Ctx) bool {
+	if c.Method() != fiber.MethodPost {
+		return false
+	}
+	path := strings.TrimSuffix(c.Path(), "/")
+	return strings.HasSuffix(path, "/generate") || strings.HasSuffix(path, "/check-conflicts")
+}
+
 // customErrorHandler handles errors globally
 func customErrorHandler(c *fiber.Ctx, err error) error {
 	code := fiber.StatusInternalServerError
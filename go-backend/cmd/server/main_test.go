@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/config"
+	"github.com/yourusername/timetable-scheduler/internal/middleware"
+)
+
+const testSecret = "test-secret"
+
+// testServer serves stand-ins for a cheap and the two expensive endpoints
+// behind the API middleware
+func testServer(cfg *config.Config) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: customErrorHandler})
+	api := app.Group("/api/v1", apiMiddleware(cfg)...)
+
+	ok := func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"message": "ok"})
+	}
+	api.Get("/timetables", ok)
+	api.Post("/timetables/check-conflicts", ok)
+	api.Post("/timetables/:id/generate", ok)
+	return app
+}
+
+// send makes a request as the given user, or without a token if user is ""
+func send(t *testing.T, app *fiber.App, tokens map[string]string, user, method, path string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	if user != "" {
+		token, ok := tokens[user]
+		if !ok {
+			var claims middleware.Claims
+			claims.AppMetadata.Role = middleware.RoleScheduler
+			claims.Subject = uuid.NewString()
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+			var err error
+			token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+			if err != nil {
+				t.Fatalf("sign token: %v", err)
+			}
+			tokens[user] = token
+		}
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	return resp
+}
+
+func TestAPIRateLimits(t *testing.T) {
+	// Every request comes from the same client IP
+	type step struct {
+		user, method, path string
+		status             int
+		limit, remaining   string // "" when the headers are absent
+	}
+	const (
+		list     = "/api/v1/timetables"
+		generate = "/api/v1/timetables/0f8fad5b-d9cb-469f-a165-70867728950e/generate"
+		check    = "/api/v1/timetables/check-conflicts"
+	)
+	limits := config.Config{
+		JWTSecret:                  testSecret,
+		RateLimitIPRequests:        10,
+		RateLimitRequests:          2,
+		RateLimitExpensiveRequests: 1,
+		RateLimitDuration:          60,
+	}
+
+	tests := []struct {
+		name  string
+		cfg   config.Config
+		steps []step
+	}{
+		{"users share an IP but not a budget", limits, []step{
+			{"alice", "GET", list, 200, "2", "1"},
+			{"alice", "GET", list, 200, "2", "0"},
+			{"alice", "GET", list, 429, "2", "0"},
+			{"bob", "GET", list, 200, "2", "1"},
+		}},
+		{"IP budget applies before the token is checked", config.Config{
+			JWTSecret:           testSecret,
+			RateLimitIPRequests: 2,
+			RateLimitRequests:   5,
+			RateLimitDuration:   60,
+		}, []step{
+			{"", "GET", list, 401, "2", "1"},
+			{"", "GET", list, 401, "2", "0"},
+			{"alice", "GET", list, 429, "2", "0"},
+		}},
+		{"expensive requests have their own budget", limits, []step{
+			{"alice", "POST", generate, 200, "1", "0"},
+			{"alice", "POST", check, 429, "1", "0"},
+			{"alice", "POST", generate + "/", 429, "1", "0"},
+			// The general budget is untouched
+			{"alice", "GET", list, 200, "2", "1"},
+			{"alice", "GET", list, 200, "2", "0"},
+			{"alice", "GET", list, 429, "2", "0"},
+			{"bob", "POST", check, 200, "1", "0"},
+		}},
+		{"zero turns a limiter off", config.Config{JWTSecret: testSecret, RateLimitDuration: 60}, []step{
+			{"alice", "GET", list, 200, "", ""},
+			{"alice", "POST", generate, 200, "", ""},
+			{"alice", "POST", generate, 200, "", ""},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := testServer(&test.cfg)
+			tokens := make(map[string]string)
+
+			for i, step := range test.steps {
+				resp := send(t, app, tokens, step.user, step.method, step.path)
+				resp.Body.Close()
+
+				got := step
+				got.status = resp.StatusCode
+				got.limit = resp.Header.Get(middleware.HeaderRateLimitLimit)
+				got.remaining = resp.Header.Get(middleware.HeaderRateLimitRemaining)
+				if got != step {
+					t.Fatalf("step %d: %s %s as %q returned %d with limit %q and %q remaining, want %d, %q and %q",
+						i, step.method, step.path, step.user, got.status, got.limit, got.remaining, step.status, step.limit, step.remaining)
+				}
+
+				reset := resp.Header.Get(middleware.HeaderRateLimitReset)
+				if step.limit != "" && reset != strconv.Itoa(test.cfg.RateLimitDuration) {
+					t.Errorf("step %d: %s is %q, want %d", i, middleware.HeaderRateLimitReset, reset, test.cfg.RateLimitDuration)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitResponse(t *testing.T) {
+	app := testServer(&config.Config{
+		JWTSecret:         testSecret,
+		RateLimitRequests: 1,
+		RateLimitDuration: 60,
+	})
+	tokens := make(map[string]string)
+
+	send(t, app, tokens, "alice", "GET", "/api/v1/timetables").Body.Close()
+	resp := send(t, app, tokens, "alice", "GET", "/api/v1/timetables")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 429 {
+		t.Fatalf("second request returned %d, want 429", resp.StatusCode)
+	}
+	if retryAfter := resp.Header.Get(fiber.HeaderRetryAfter); retryAfter != "60" {
+		t.Errorf("Retry-After is %q, want 60", retryAfter)
+	}
+
+	// The app's error handler renders the rejection
+	var body struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !body.Error || !strings.HasPrefix(body.Message, "Rate limit exceeded") {
+		t.Fatalf("429 body is %+v", body)
+	}
+}
+
+func TestRateLimitWindowResets(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: customErrorHandler})
+	app.Use(middleware.RateLimit(middleware.RateLimitConfig{
+		Max:    1,
+		Window: 100 * time.Millisecond,
+		Key:    middleware.ByIP,
+	}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(204)
+	})
+
+	for i, want := range []int{204, 429, -1, 204, 429} {
+		if want < 0 {
+			time.Sleep(150 * time.Millisecond)
+			continue
+		}
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil), -1)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != want {
+			t.Fatalf("request %d returned %d, want %d", i, resp.StatusCode, want)
+		}
+		// Less than a second left still reads as one
+		if reset := resp.Header.Get(middleware.HeaderRateLimitReset); reset != "1" {
+			t.Errorf("request %d: %s is %q, want 1", i, middleware.HeaderRateLimitReset, reset)
+		}
+	}
+}
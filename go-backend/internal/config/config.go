@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -34,6 +35,9 @@ type Config struct {
 	OptimizationTimeout   int
 	OptimizationWorkers   int
 	OptimizationAlgorithm string
+	// Course type -> room types in order of preference, overriding the
+	// engine's defaults for the course types it lists
+	OptimizationRoomCompatibility map[string][]string
 
 	// Storage
 	StoragePath   string
@@ -52,8 +56,10 @@ type Config struct {
 	LogLevel string
 
 	// Rate Limiting
-	RateLimitRequests int
-	RateLimitDuration int
+	RateLimitRequests          int // per user
+	RateLimitIPRequests        int // per client IP
+	RateLimitExpensiveRequests int // per user, for generation and conflict checks
+	RateLimitDuration          int // window in seconds
 }
 
 // Load reads configuration from environment variables
@@ -104,10 +110,18 @@ func Load() (*Config, error) {
 		LogLevel: getEnv("LOG_LEVEL", "info"),
 
 		// Rate Limiting
-		RateLimitRequests: getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
-		RateLimitDuration: getEnvAsInt("RATE_LIMIT_DURATION", 60),
+		RateLimitRequests:          getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
+		RateLimitIPRequests:        getEnvAsInt("RATE_LIMIT_IP_REQUESTS", 300),
+		RateLimitExpensiveRequests: getEnvAsInt("RATE_LIMIT_EXPENSIVE_REQUESTS", 5),
+		RateLimitDuration:          getEnvAsInt("RATE_LIMIT_DURATION", 60),
 	}
 
+	roomCompatibility, err := parseRoomCompatibility(getEnv("OPTIMIZATION_ROOM_COMPATIBILITY", ""))
+	if err != nil {
+		return nil, err
+	}
+	cfg.OptimizationRoomCompatibility = roomCompatibility
+
 	// Build DATABASE_URL if not provided
 	if cfg.DatabaseURL == "" {
 		cfg.DatabaseURL = fmt.Sprintf(
@@ -168,3 +182,34 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// parseRoomCompatibility reads course type to room type mappings written as
+// "SEMINAR=SEMINAR_HALL,CLASSROOM;PROJECT=LAB"
+func parseRoomCompatibility(value string) (map[string][]string, error) {
+	compatibility := make(map[string][]string)
+
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		courseType, roomTypes, ok := strings.Cut(entry, "=")
+		courseType = strings.ToUpper(strings.TrimSpace(courseType))
+		if !ok || courseType == "" {
+			return nil, fmt.Errorf("OPTIMIZATION_ROOM_COMPATIBILITY: invalid entry %q", entry)
+		}
+
+		types := []string{}
+		for _, roomType := range strings.Split(roomTypes, ",") {
+			if roomType = strings.ToUpper(strings.TrimSpace(roomType)); roomType != "" {
+				types = append(types, roomType)
+			}
+		}
+		if len(types) == 0 {
+			return nil, fmt.Errorf("OPTIMIZATION_ROOM_COMPATIBILITY: no room types for %s", courseType)
+		}
+		compatibility[courseType] = types
+	}
+
+	return compatibility, nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRoomCompatibility(t *testing.T) {
+	got, err := parseRoomCompatibility(" seminar = SEMINAR_HALL, classroom ;PROJECT=LAB;")
+	if err != nil {
+		t.Fatalf("parseRoomCompatibility: %v", err)
+	}
+	want := map[string][]string{
+		"SEMINAR": {"SEMINAR_HALL", "CLASSROOM"},
+		"PROJECT": {"LAB"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if got, err := parseRoomCompatibility(""); err != nil || len(got) != 0 {
+		t.Fatalf("empty value gave %v, %v", got, err)
+	}
+
+	for _, value := range []string{"SEMINAR", "=LAB", "SEMINAR=", "SEMINAR= , "} {
+		if _, err := parseRoomCompatibility(value); err == nil {
+			t.Errorf("parseRoomCompatibility(%q) accepted an invalid entry", value)
+		}
+	}
+}
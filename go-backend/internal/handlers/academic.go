@@ -5,6 +5,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/yourusername/timetable-scheduler/internal/database"
 	"github.com/yourusername/timetable-scheduler/internal/models"
+	"gorm.io/gorm/clause"
 )
 
 // GetAcademicYears retrieves all academic years
@@ -102,13 +103,17 @@ func UpdateAcademicYear(c *fiber.Ctx) error {
 		})
 	}
 
+	// The body may not retarget the update at another row
+	base := year.Base
 	if err := c.BodyParser(&year); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
-	if err := database.DB.Save(&year).Error; err != nil {
+	year.Base = base
+
+	if err := database.DB.Omit(clause.Associations).Save(&year).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update academic year",
 		})
@@ -236,13 +241,17 @@ func UpdateSemester(c *fiber.Ctx) error {
 		})
 	}
 
+	// The body may not retarget the update at another row
+	base := semester.Base
 	if err := c.BodyParser(&semester); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
-	if err := database.DB.Save(&semester).Error; err != nil {
+	semester.Base = base
+
+	if err := database.DB.Omit(clause.Associations).Save(&semester).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update semester",
 		})
@@ -271,6 +280,12 @@ func DeleteSemester(c *fiber.Ctx) error {
 		})
 	}
 
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Semester not found",
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "Semester deleted successfully",
 	})
@@ -281,7 +296,7 @@ func DeleteSemester(c *fiber.Ctx) error {
 func GetDepartments(c *fiber.Ctx) error {
 	var departments []models.Department
 
-	result := database.DB.Order("name ASC").Find(&departments)
+	result := scopeDepartments(c, database.DB.Order("name ASC")).Find(&departments)
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to fetch departments",
@@ -305,7 +320,7 @@ func GetDepartment(c *fiber.Ctx) error {
 	}
 
 	var department models.Department
-	result := database.DB.Preload("Programs").Preload("Courses").Preload("Faculty").First(&department, deptID)
+	result := scopeDepartments(c, database.DB).Preload("Programs").Preload("Courses").Preload("Faculty").First(&department, deptID)
 	if result.Error != nil {
 		return c.Status(404).JSON(fiber.Map{
 			"error": "Department not found",
@@ -356,13 +371,17 @@ func UpdateDepartment(c *fiber.Ctx) error {
 		})
 	}
 
+	// The body may not retarget the update at another row
+	base := department.Base
 	if err := c.BodyParser(&department); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
-	if err := database.DB.Save(&department).Error; err != nil {
+	department.Base = base
+
+	if err := database.DB.Omit(clause.Associations).Save(&department).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update department",
 		})
@@ -391,6 +410,12 @@ func DeleteDepartment(c *fiber.Ctx) error {
 		})
 	}
 
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Department not found",
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "Department deleted successfully",
 	})
@@ -401,7 +426,14 @@ func DeleteDepartment(c *fiber.Ctx) error {
 func GetPrograms(c *fiber.Ctx) error {
 	var programs []models.Program
 
-	result := database.DB.Preload("Department").Order("name ASC").Find(&programs)
+	query := scopePrograms(c, database.DB).Preload("Department").Order("name ASC")
+
+	// Filter by department if provided
+	if deptID := c.Query("department_id"); deptID != "" {
+		query = query.Where("department_id = ?", deptID)
+	}
+
+	result := query.Find(&programs)
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to fetch programs",
@@ -425,7 +457,7 @@ func GetProgram(c *fiber.Ctx) error {
 	}
 
 	var program models.Program
-	result := database.DB.Preload("Department").First(&program, programID)
+	result := scopePrograms(c, database.DB).Preload("Department").First(&program, programID)
 	if result.Error != nil {
 		return c.Status(404).JSON(fiber.Map{
 			"error": "Program not found",
@@ -446,6 +478,10 @@ func CreateProgram(c *fiber.Ctx) error {
 		})
 	}
 
+	if !inDepartment(c, program.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	result := database.DB.Create(&program)
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -476,13 +512,26 @@ func UpdateProgram(c *fiber.Ctx) error {
 		})
 	}
 
+	if !inDepartment(c, program.DepartmentID) {
+		return outOfScope(c)
+	}
+
+	// The body may not retarget the update at another row
+	base := program.Base
 	if err := c.BodyParser(&program); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
-	if err := database.DB.Save(&program).Error; err != nil {
+	program.Base = base
+
+	// The program may not be moved out of the caller's department either
+	if !inDepartment(c, program.DepartmentID) {
+		return outOfScope(c)
+	}
+
+	if err := database.DB.Omit(clause.Associations).Save(&program).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update program",
 		})
@@ -504,6 +553,17 @@ func DeleteProgram(c *fiber.Ctx) error {
 		})
 	}
 
+	var program models.Program
+	if err := database.DB.First(&program, programID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Program not found",
+		})
+	}
+
+	if !inDepartment(c, program.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	result := database.DB.Delete(&models.Program{}, programID)
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
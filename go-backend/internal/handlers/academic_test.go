@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestAcademicYearRoutes(t *testing.T) {
+	app := newTestApp(t)
+
+	call(t, app, "POST", "/api/v1/academic/years", fiber.Map{"year": "2025-2026"}, 400)
+	call(t, app, "POST", "/api/v1/academic/years", fiber.Map{
+		"year":       "2025-2026",
+		"start_date": time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+		"end_date":   time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+	}, 400)
+
+	response := call(t, app, "POST", "/api/v1/academic/years", fiber.Map{
+		"year":       "2025-2026",
+		"start_date": time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		"end_date":   time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+	}, 201)
+	var year models.AcademicYear
+	decode(t, response.Data, &year)
+	path := "/api/v1/academic/years/" + year.ID.String()
+
+	seed(t, &models.AcademicYear{
+		Year:      "2024-2025",
+		StartDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC),
+	})
+
+	// Newest first
+	response = call(t, app, "GET", "/api/v1/academic/years", nil, 200)
+	var years []models.AcademicYear
+	decode(t, response.Data, &years)
+	if len(years) != 2 || years[0].Year != "2025-2026" || years[1].Year != "2024-2025" {
+		t.Fatalf("academic years are %+v, want 2025-2026 then 2024-2025", years)
+	}
+
+	response = call(t, app, "GET", path, nil, 200)
+	var fetched models.AcademicYear
+	decode(t, response.Data, &fetched)
+	if fetched.ID != year.ID || fetched.Year != "2025-2026" {
+		t.Fatalf("fetched academic year %s %s, want %s 2025-2026", fetched.ID, fetched.Year, year.ID)
+	}
+
+	response = call(t, app, "PUT", path, fiber.Map{"is_active": true}, 200)
+	var updated models.AcademicYear
+	decode(t, response.Data, &updated)
+	if updated.ID != year.ID || !updated.IsActive || updated.Year != "2025-2026" {
+		t.Fatalf("updated academic year is %s %s, active %v", updated.ID, updated.Year, updated.IsActive)
+	}
+
+	call(t, app, "GET", "/api/v1/academic/years/not-a-uuid", nil, 400)
+	call(t, app, "DELETE", path, nil, 200)
+	call(t, app, "GET", path, nil, 404)
+	call(t, app, "PUT", path, fiber.Map{"is_active": false}, 404)
+	call(t, app, "DELETE", path, nil, 404)
+}
+
+func TestSemesterRoutes(t *testing.T) {
+	app := newTestApp(t)
+	existing := seedSemester(t)
+
+	year := models.AcademicYear{
+		Year:      "2026-2027",
+		StartDate: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+	seed(t, &year)
+
+	response := call(t, app, "POST", "/api/v1/academic/semesters", fiber.Map{
+		"academic_year_id": year.ID,
+		"name":             "Fall 2026",
+		"type":             "ODD",
+		"semester_number":  1,
+		"start_date":       time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC),
+		"end_date":         time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC),
+	}, 201)
+	var semester models.Semester
+	decode(t, response.Data, &semester)
+	path := "/api/v1/academic/semesters/" + semester.ID.String()
+
+	for _, test := range []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Fall 2026", "Fall 2025"}},
+		{"?academic_year_id=" + year.ID.String(), []string{"Fall 2026"}},
+		{"?academic_year_id=" + existing.AcademicYearID.String(), []string{"Fall 2025"}},
+	} {
+		response := call(t, app, "GET", "/api/v1/academic/semesters"+test.query, nil, 200)
+		var semesters []models.Semester
+		decode(t, response.Data, &semesters)
+
+		names := make([]string, 0, len(semesters))
+		for _, semester := range semesters {
+			names = append(names, semester.Name)
+		}
+		if !slices.Equal(names, test.want) || response.Count != len(test.want) {
+			t.Errorf("GET /academic/semesters%s returned %v (count %d), want %v", test.query, names, response.Count, test.want)
+		}
+	}
+
+	response = call(t, app, "GET", path, nil, 200)
+	var fetched models.Semester
+	decode(t, response.Data, &fetched)
+	if fetched.ID != semester.ID || fetched.AcademicYear.Year != "2026-2027" {
+		t.Fatalf("fetched semester %s of year %q, want %s of 2026-2027", fetched.ID, fetched.AcademicYear.Year, semester.ID)
+	}
+
+	response = call(t, app, "PUT", path, fiber.Map{"name": "Autumn 2026"}, 200)
+	var updated models.Semester
+	decode(t, response.Data, &updated)
+	if updated.ID != semester.ID || updated.Name != "Autumn 2026" || updated.SemesterNumber != 1 {
+		t.Fatalf("updated semester is %s %q, number %d", updated.ID, updated.Name, updated.SemesterNumber)
+	}
+
+	call(t, app, "GET", "/api/v1/academic/semesters/not-a-uuid", nil, 400)
+	call(t, app, "DELETE", path, nil, 200)
+	call(t, app, "GET", path, nil, 404)
+	call(t, app, "DELETE", path, nil, 404)
+}
+
+func TestDepartmentRoutes(t *testing.T) {
+	app := newTestApp(t)
+	seedDepartment(t, "MATH")
+
+	response := call(t, app, "POST", "/api/v1/academic/departments", fiber.Map{
+		"name": "Computer Science",
+		"code": "CS",
+	}, 201)
+	var department models.Department
+	decode(t, response.Data, &department)
+	path := "/api/v1/academic/departments/" + department.ID.String()
+
+	// By name
+	response = call(t, app, "GET", "/api/v1/academic/departments", nil, 200)
+	var departments []models.Department
+	decode(t, response.Data, &departments)
+	if len(departments) != 2 || departments[0].Code != "CS" || departments[1].Code != "MATH" {
+		t.Fatalf("departments are %+v, want CS then MATH", departments)
+	}
+
+	seedCourse(t, "CS101", "THEORY")
+	seed(t, &models.Course{
+		Code:         "CS102",
+		Name:         "Course CS102",
+		DepartmentID: &department.ID,
+		CourseType:   "THEORY",
+		Credits:      3,
+		HoursPerWeek: 3,
+	})
+
+	response = call(t, app, "GET", path, nil, 200)
+	var fetched models.Department
+	decode(t, response.Data, &fetched)
+	if fetched.ID != department.ID || len(fetched.Courses) != 1 || fetched.Courses[0].Code != "CS102" {
+		t.Fatalf("fetched department %s has courses %+v, want %s with CS102", fetched.ID, fetched.Courses, department.ID)
+	}
+
+	response = call(t, app, "PUT", path, fiber.Map{"head_of_department": "Dr. Knuth"}, 200)
+	var updated models.Department
+	decode(t, response.Data, &updated)
+	if updated.ID != department.ID || updated.HeadOfDepartment != "Dr. Knuth" || updated.Code != "CS" {
+		t.Fatalf("updated department is %s %s headed by %q", updated.ID, updated.Code, updated.HeadOfDepartment)
+	}
+
+	call(t, app, "GET", "/api/v1/academic/departments/not-a-uuid", nil, 400)
+	call(t, app, "DELETE", path, nil, 200)
+	call(t, app, "GET", path, nil, 404)
+	call(t, app, "DELETE", path, nil, 404)
+}
+
+func TestProgramRoutes(t *testing.T) {
+	app := newTestApp(t)
+	cs := seedDepartment(t, "CS")
+	math := seedDepartment(t, "MATH")
+
+	body := func(name, code string, departmentID uuid.UUID) fiber.Map {
+		return fiber.Map{
+			"name":           name,
+			"code":           code,
+			"program_type":   "FYUP",
+			"department_id":  departmentID,
+			"duration_years": 4,
+			"total_credits":  160,
+		}
+	}
+	response := call(t, app, "POST", "/api/v1/academic/programs", body("B.Sc. Computer Science", "BSC-CS", cs.ID), 201)
+	var program models.Program
+	decode(t, response.Data, &program)
+	path := "/api/v1/academic/programs/" + program.ID.String()
+
+	call(t, app, "POST", "/api/v1/academic/programs", body("B.Sc. Mathematics", "BSC-MATH", math.ID), 201)
+
+	for _, test := range []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"BSC-CS", "BSC-MATH"}},
+		{"?department_id=" + math.ID.String(), []string{"BSC-MATH"}},
+	} {
+		response := call(t, app, "GET", "/api/v1/academic/programs"+test.query, nil, 200)
+		var programs []models.Program
+		decode(t, response.Data, &programs)
+
+		codes := make([]string, 0, len(programs))
+		for _, program := range programs {
+			codes = append(codes, program.Code)
+		}
+		if !slices.Equal(codes, test.want) || response.Count != len(test.want) {
+			t.Errorf("GET /academic/programs%s returned %v (count %d), want %v", test.query, codes, response.Count, test.want)
+		}
+	}
+
+	response = call(t, app, "GET", path, nil, 200)
+	var fetched models.Program
+	decode(t, response.Data, &fetched)
+	if fetched.ID != program.ID || fetched.Department == nil || fetched.Department.Code != "CS" {
+		t.Fatalf("fetched program %s has department %+v, want %s in CS", fetched.ID, fetched.Department, program.ID)
+	}
+
+	response = call(t, app, "PUT", path, fiber.Map{"total_credits": 176}, 200)
+	var updated models.Program
+	decode(t, response.Data, &updated)
+	if updated.ID != program.ID || updated.TotalCredits != 176 || updated.Code != "BSC-CS" {
+		t.Fatalf("updated program is %s %s with %d credits", updated.ID, updated.Code, updated.TotalCredits)
+	}
+
+	call(t, app, "GET", "/api/v1/academic/programs/not-a-uuid", nil, 400)
+	call(t, app, "DELETE", path, nil, 200)
+	call(t, app, "GET", path, nil, 404)
+	call(t, app, "DELETE", path, nil, 404)
+}
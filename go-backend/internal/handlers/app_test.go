@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/config"
+	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/middleware"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// The end-to-end tests run the routes against the Postgres database named by
+// TEST_DATABASE_URL, whose tables they empty before each test. Without it
+// they are skipped.
+
+// testSecret signs the tokens of the test requests
+const testSecret = "test-secret"
+
+// testModels are the tables the tests create and empty
+var testModels = []interface{}{
+	&models.AcademicYear{},
+	&models.Semester{},
+	&models.Department{},
+	&models.Program{},
+	&models.CourseCategory{},
+	&models.Course{},
+	&models.Faculty{},
+	&models.FacultyAvailability{},
+	&models.FacultyCourseExpertise{},
+	&models.Room{},
+	&models.Student{},
+	&models.StudentEnrollment{},
+	&models.TimetableTemplate{},
+	&models.TimeSlot{},
+	&models.ScheduledClass{},
+	&models.TimetableConstraint{},
+	&models.ConflictLog{},
+	&models.GenerationRun{},
+	&models.TimetableVersion{},
+}
+
+func TestMain(m *testing.M) {
+	if databaseURL := os.Getenv("TEST_DATABASE_URL"); databaseURL != "" {
+		if err := setupTestDatabase(databaseURL); err != nil {
+			log.Fatalf("Failed to set up the test database: %v", err)
+		}
+	}
+	os.Exit(m.Run())
+}
+
+func setupTestDatabase(databaseURL string) error {
+	if err := database.Connect(databaseURL, false); err != nil {
+		return err
+	}
+	if err := database.DB.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; err != nil {
+		return err
+	}
+	return database.DB.AutoMigrate(testModels...)
+}
+
+// testApp serves the API routes the way cmd/server/main.go mounts them
+func testApp() *fiber.App {
+	app := fiber.New()
+	SetupRoutes(app.Group("/api/v1", middleware.Authenticate(testSecret)), &config.Config{})
+	return app
+}
+
+// newTestApp returns the API on an empty test database, or skips the test
+// when there is none
+func newTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+
+	if database.DB == nil {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	tables := make([]string, 0, len(testModels))
+	for _, model := range testModels {
+		stmt := &gorm.Statement{DB: database.DB}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("parse %T: %v", model, err)
+		}
+		tables = append(tables, stmt.Schema.Table)
+	}
+	if err := database.DB.Exec("TRUNCATE " + strings.Join(tables, ", ") + " CASCADE").Error; err != nil {
+		t.Fatalf("empty the test tables: %v", err)
+	}
+
+	return testApp()
+}
+
+// testResponse holds the fields the handlers respond with
+type testResponse struct {
+	Data      json.RawMessage `json:"data"`
+	Count     int             `json:"count"`
+	Message   string          `json:"message"`
+	Error     string          `json:"error"`
+	Version   int             `json:"version"`
+	Conflicts []Conflict      `json:"conflicts"`
+}
+
+// call sends a request as an admin and checks its status
+func call(t *testing.T, app *fiber.App, method, path string, body interface{}, wantStatus int) testResponse {
+	t.Helper()
+
+	return callAs(t, app, adminToken(t), method, path, body, wantStatus)
+}
+
+// callAs sends a request with the given bearer token, if any, and checks its
+// status
+func callAs(t *testing.T, app *fiber.App, token, method, path string, body interface{}, wantStatus int) testResponse {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("marshal %s %s body: %v", method, path, err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	var response testResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("%s %s: decode response: %v", method, path, err)
+	}
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("%s %s returned %d (%s), want %d", method, path, resp.StatusCode, response.Error, wantStatus)
+	}
+	return response
+}
+
+// decode unmarshals the data of a response
+func decode(t *testing.T, data json.RawMessage, v interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+}
+
+func adminToken(t *testing.T) string {
+	t.Helper()
+
+	return roleToken(t, middleware.RoleAdmin, uuid.New())
+}
+
+// roleToken signs a token for a user with a role and no department or
+// program
+func roleToken(t *testing.T, role string, userID uuid.UUID) string {
+	t.Helper()
+
+	var claims middleware.Claims
+	claims.AppMetadata.Role = role
+	claims.Subject = userID.String()
+	return signToken(t, claims)
+}
+
+func signToken(t *testing.T, claims middleware.Claims) string {
+	t.Helper()
+
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+// seed inserts a record straight into the test database
+func seed(t *testing.T, record interface{}) {
+	t.Helper()
+
+	if err := database.DB.Create(record).Error; err != nil {
+		t.Fatalf("seed %T: %v", record, err)
+	}
+}
+
+func seedDepartment(t *testing.T, code string) models.Department {
+	t.Helper()
+
+	department := models.Department{Name: code + " Department", Code: code}
+	seed(t, &department)
+	return department
+}
+
+func seedSemester(t *testing.T) models.Semester {
+	t.Helper()
+
+	year := models.AcademicYear{
+		Year:      "2025-2026",
+		StartDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+	seed(t, &year)
+
+	semester := models.Semester{
+		AcademicYearID: year.ID,
+		Name:           "Fall 2025",
+		Type:           "ODD",
+		SemesterNumber: 1,
+		StartDate:      time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:        time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+	}
+	seed(t, &semester)
+	return semester
+}
+
+func seedCourse(t *testing.T, code, courseType string) models.Course {
+	t.Helper()
+
+	course := models.Course{
+		Code:         code,
+		Name:         "Course " + code,
+		CourseType:   courseType,
+		Credits:      3,
+		HoursPerWeek: 3,
+	}
+	seed(t, &course)
+	return course
+}
+
+func seedFaculty(t *testing.T, employeeID string) models.Faculty {
+	t.Helper()
+
+	faculty := models.Faculty{
+		EmployeeID:  employeeID,
+		FirstName:   "Faculty",
+		LastName:    employeeID,
+		Email:       strings.ToLower(employeeID) + "@example.edu",
+		Designation: "Professor",
+	}
+	seed(t, &faculty)
+	return faculty
+}
+
+func seedRoom(t *testing.T, number, roomType string, capacity int) models.Room {
+	t.Helper()
+
+	room := models.Room{
+		RoomNumber: number,
+		Building:   "Main",
+		RoomType:   roomType,
+		Capacity:   capacity,
+	}
+	seed(t, &room)
+	return room
+}
+
+// createTimetable creates a timetable through the API, which also gives it
+// its default time slots
+func createTimetable(t *testing.T, app *fiber.App, semesterID uuid.UUID) models.TimetableTemplate {
+	t.Helper()
+
+	response := call(t, app, "POST", "/api/v1/timetables", fiber.Map{
+		"name":        "Fall 2025 - Computer Science",
+		"semester_id": semesterID,
+	}, 201)
+
+	var timetable models.TimetableTemplate
+	decode(t, response.Data, &timetable)
+	return timetable
+}
+
+// timeSlot returns the timetable's slot starting at the given time of a day
+func timeSlot(t *testing.T, timetableID uuid.UUID, day int, start string) models.TimeSlot {
+	t.Helper()
+
+	var slot models.TimeSlot
+	err := database.DB.
+		Where("timetable_id = ? AND day_of_week = ? AND start_time = ?", timetableID, day, start).
+		First(&slot).Error
+	if err != nil {
+		t.Fatalf("find the %s slot of day %d: %v", start, day, err)
+	}
+	return slot
+}
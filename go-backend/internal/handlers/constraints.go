@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+	"github.com/yourusername/timetable-scheduler/internal/optimization"
+)
+
+// constraintRequest is the body of the constraint create and update
+// endpoints. Fields left out keep their current value, or on create the
+// constraint type's defaults.
+type constraintRequest struct {
+	ConstraintType   *string                `json:"constraint_type"`
+	ConstraintData   map[string]interface{} `json:"constraint_data"`
+	Priority         *int                   `json:"priority"`
+	IsHardConstraint *bool                  `json:"is_hard_constraint"`
+	IsDisabled       *bool                  `json:"is_disabled"`
+}
+
+// GetConstraintTypes lists the constraint types timetables can configure,
+// with the parameters each one accepts in constraint_data. Default types are
+// enforced on every timetable unless one of its rows sets is_disabled.
+func GetConstraintTypes(c *fiber.Ctx) error {
+	types := optimization.ConstraintTypes()
+
+	return c.JSON(fiber.Map{
+		"data":  types,
+		"count": len(types),
+	})
+}
+
+// GetTimetableConstraints lists the constraints configured for a timetable
+func GetTimetableConstraints(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	timetableID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	var constraints []models.TimetableConstraint
+	result := database.DB.
+		Where("timetable_id = ?", timetableID).
+		Order("created_at").
+		Find(&constraints)
+
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to fetch constraints",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data":  constraints,
+		"count": len(constraints),
+	})
+}
+
+// CreateTimetableConstraint adds a constraint to a timetable
+func CreateTimetableConstraint(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	timetableID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	// Check if timetable exists
+	var timetable models.TimetableTemplate
+	if err := database.DB.First(&timetable, timetableID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Timetable not found",
+		})
+	}
+
+	var request constraintRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if request.ConstraintType == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "constraint_type is required",
+		})
+	}
+	constraintType, ok := optimization.LookupConstraintType(*request.ConstraintType)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("Unknown constraint type %s", *request.ConstraintType),
+		})
+	}
+
+	constraint := models.TimetableConstraint{
+		TimetableID:      timetableID,
+		ConstraintType:   constraintType.Type,
+		ConstraintData:   map[string]interface{}{},
+		Priority:         1,
+		IsHardConstraint: constraintType.Hard,
+	}
+	applyConstraintRequest(&constraint, request)
+
+	if err := validateConstraint(&constraint); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if constraintTypeTaken(timetableID, constraint.ConstraintType, uuid.Nil) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "This constraint type is already configured for the timetable",
+		})
+	}
+
+	if err := database.DB.Create(&constraint).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to create constraint",
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Constraint created successfully",
+		"data":    constraint,
+	})
+}
+
+// UpdateTimetableConstraint updates a constraint of a timetable
+func UpdateTimetableConstraint(c *fiber.Ctx) error {
+	timetableID := c.Params("id")
+	constraintID, err := uuid.Parse(c.Params("constraint_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid constraint ID format",
+		})
+	}
+
+	var constraint models.TimetableConstraint
+	if err := database.DB.Where("id = ? AND timetable_id = ?", constraintID, timetableID).First(&constraint).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Constraint not found",
+		})
+	}
+
+	var request constraintRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	applyConstraintRequest(&constraint, request)
+
+	if err := validateConstraint(&constraint); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if constraintTypeTaken(constraint.TimetableID, constraint.ConstraintType, constraint.ID) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "This constraint type is already configured for the timetable",
+		})
+	}
+
+	if err := database.DB.Save(&constraint).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to update constraint",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Constraint updated successfully",
+		"data":    constraint,
+	})
+}
+
+// DeleteTimetableConstraint removes a constraint from a timetable
+func DeleteTimetableConstraint(c *fiber.Ctx) error {
+	timetableID := c.Params("id")
+	constraintID, err := uuid.Parse(c.Params("constraint_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid constraint ID format",
+		})
+	}
+
+	result := database.DB.Where("id = ? AND timetable_id = ?", constraintID, timetableID).Delete(&models.TimetableConstraint{})
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to delete constraint",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Constraint not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Constraint deleted successfully",
+	})
+}
+
+// applyConstraintRequest copies the fields present in the request onto the
+// constraint
+func applyConstraintRequest(constraint *models.TimetableConstraint, request constraintRequest) {
+	if request.ConstraintType != nil {
+		constraint.ConstraintType = *request.ConstraintType
+	}
+	if request.ConstraintData != nil {
+		constraint.ConstraintData = request.ConstraintData
+	}
+	if request.Priority != nil {
+		constraint.Priority = *request.Priority
+	}
+	if request.IsHardConstraint != nil {
+		constraint.IsHardConstraint = *request.IsHardConstraint
+	}
+	if request.IsDisabled != nil {
+		constraint.IsDisabled = *request.IsDisabled
+	}
+}
+
+// validateConstraint checks the constraint type, its data against the type's
+// parameter schema and the priority range
+func validateConstraint(constraint *models.TimetableConstraint) error {
+	constraintType, ok := optimization.LookupConstraintType(constraint.ConstraintType)
+	if !ok {
+		return fmt.Errorf("Unknown constraint type %s", constraint.ConstraintType)
+	}
+
+	if constraint.Priority < 1 || constraint.Priority > 10 {
+		return errors.New("Priority must be between 1 and 10")
+	}
+
+	if constraintType.Required && !constraint.IsHardConstraint {
+		return fmt.Errorf("%s is always a hard constraint", constraintType.Type)
+	}
+
+	if constraintType.Required && constraint.IsDisabled {
+		return fmt.Errorf("%s cannot be disabled", constraintType.Type)
+	}
+
+	if err := constraintType.Validate(constraint.ConstraintData); err != nil {
+		return fmt.Errorf("Invalid constraint_data: %w", err)
+	}
+
+	return nil
+}
+
+// constraintTypeTaken reports whether another constraint of the timetable,
+// other than the one with the given ID, already has this type
+func constraintTypeTaken(timetableID uuid.UUID, constraintType string, exceptID uuid.UUID) bool {
+	var count int64
+	database.DB.Model(&models.TimetableConstraint{}).
+		Where("timetable_id = ? AND constraint_type = ? AND id != ?", timetableID, constraintType, exceptID).
+		Count(&count)
+	return count > 0
+}
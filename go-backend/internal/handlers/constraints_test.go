@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+	"github.com/yourusername/timetable-scheduler/internal/optimization"
+)
+
+func TestGetConstraintTypes(t *testing.T) {
+	// Constraint types come from the registry, so this runs without a database
+	req := httptest.NewRequest("GET", "/api/v1/constraint-types", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+adminToken(t))
+	resp, err := testApp().Test(req, -1)
+	if err != nil {
+		t.Fatalf("GET /constraint-types: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /constraint-types returned %d, want 200", resp.StatusCode)
+	}
+
+	var body struct {
+		Data  []optimization.ConstraintType `json:"data"`
+		Count int                           `json:"count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Count == 0 || body.Count != len(body.Data) {
+		t.Fatalf("got %d constraint types with count %d", len(body.Data), body.Count)
+	}
+
+	for _, constraintType := range body.Data {
+		switch {
+		case constraintType.Required:
+			if !strings.HasSuffix(constraintType.Description, "(always enforced)") {
+				t.Errorf("%s is required but its description is %q", constraintType.Type, constraintType.Description)
+			}
+		case constraintType.Default:
+			if !strings.Contains(constraintType.Description, "is_disabled") {
+				t.Errorf("%s is a default but its description is %q", constraintType.Type, constraintType.Description)
+			}
+		}
+	}
+}
+
+func TestConstraintRoutes(t *testing.T) {
+	app := newTestApp(t)
+	semester := seedSemester(t)
+	timetable := createTimetable(t, app, semester.ID)
+	path := "/api/v1/timetables/" + timetable.ID.String() + "/constraints"
+
+	body := fiber.Map{
+		"constraint_type": optimization.ConstraintFacultyWorkloadLimit,
+		"constraint_data": fiber.Map{"max_hours": 16},
+	}
+	response := call(t, app, "POST", path, body, 201)
+	var constraint models.TimetableConstraint
+	decode(t, response.Data, &constraint)
+	if constraint.Priority != 1 || !constraint.IsHardConstraint || constraint.IsDisabled {
+		t.Fatalf("created constraint has priority %d, hard %v, disabled %v; want the type's defaults",
+			constraint.Priority, constraint.IsHardConstraint, constraint.IsDisabled)
+	}
+	constraintPath := path + "/" + constraint.ID.String()
+
+	call(t, app, "POST", path, body, 409)
+	call(t, app, "POST", path, fiber.Map{"constraint_type": "NO_SUCH_CONSTRAINT"}, 400)
+	call(t, app, "POST", path, fiber.Map{
+		"constraint_type": optimization.ConstraintNoFacultyDoubleBooking,
+		"is_disabled":     true,
+	}, 400)
+	call(t, app, "POST", "/api/v1/timetables/"+uuid.NewString()+"/constraints", body, 404)
+
+	call(t, app, "PUT", constraintPath, fiber.Map{"constraint_data": fiber.Map{"max_hours": 500}}, 400)
+	call(t, app, "PUT", constraintPath, fiber.Map{"priority": 11}, 400)
+
+	response = call(t, app, "PUT", constraintPath, fiber.Map{"priority": 5, "is_disabled": true}, 200)
+	var updated models.TimetableConstraint
+	decode(t, response.Data, &updated)
+	if updated.ID != constraint.ID || updated.Priority != 5 || !updated.IsDisabled || updated.ConstraintData["max_hours"] != float64(16) {
+		t.Fatalf("updated constraint is %+v", updated)
+	}
+
+	response = call(t, app, "GET", path, nil, 200)
+	if response.Count != 1 {
+		t.Fatalf("timetable has %d constraints, want 1", response.Count)
+	}
+
+	call(t, app, "PUT", path+"/"+uuid.NewString(), fiber.Map{"priority": 2}, 404)
+	call(t, app, "DELETE", constraintPath, nil, 200)
+	call(t, app, "DELETE", constraintPath, nil, 404)
+}
@@ -5,22 +5,32 @@ import (
 	"github.com/google/uuid"
 	"github.com/yourusername/timetable-scheduler/internal/database"
 	"github.com/yourusername/timetable-scheduler/internal/models"
+	"gorm.io/gorm/clause"
 )
 
 // GetCourses retrieves all courses
 func GetCourses(c *fiber.Ctx) error {
 	var courses []models.Course
 
-	query := database.DB.Preload("Department").Order("code ASC")
+	query := scopeByDepartment(c, database.DB).Preload("Department").Order("code ASC")
 
 	// Filter by department if provided
 	if deptID := c.Query("department_id"); deptID != "" {
 		query = query.Where("department_id = ?", deptID)
 	}
 
-	// Filter by is_lab if provided
+	// Filter by course type if provided
+	if courseType := c.Query("course_type"); courseType != "" {
+		query = query.Where("course_type = ?", courseType)
+	}
+
+	// is_lab is shorthand for course type LAB
 	if isLab := c.Query("is_lab"); isLab != "" {
-		query = query.Where("is_lab = ?", isLab)
+		if c.QueryBool("is_lab") {
+			query = query.Where("course_type = ?", "LAB")
+		} else {
+			query = query.Where("course_type <> ?", "LAB")
+		}
 	}
 
 	result := query.Find(&courses)
@@ -48,7 +58,7 @@ func GetCourse(c *fiber.Ctx) error {
 	}
 
 	var course models.Course
-	result := database.DB.Preload("Department").First(&course, courseID)
+	result := scopeByDepartment(c, database.DB).Preload("Department").First(&course, courseID)
 	if result.Error != nil {
 		return c.Status(404).JSON(fiber.Map{
 			"error": "Course not found",
@@ -83,6 +93,10 @@ func CreateCourse(c *fiber.Ctx) error {
 		})
 	}
 
+	if !inDepartment(c, course.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	// Check for duplicate code
 	var existing models.Course
 	if err := database.DB.Where("code = ?", course.Code).First(&existing).Error; err == nil {
@@ -125,15 +139,28 @@ func UpdateCourse(c *fiber.Ctx) error {
 		})
 	}
 
+	if !inDepartment(c, course.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	// Store original code to check for duplicates
 	originalCode := course.Code
 
+	// The body may not retarget the update at another row
+	base := course.Base
 	if err := c.BodyParser(&course); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
+	course.Base = base
+
+	// The course may not be moved out of the caller's department either
+	if !inDepartment(c, course.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	// Check for duplicate code if changed
 	if course.Code != originalCode {
 		var existing models.Course
@@ -144,7 +171,7 @@ func UpdateCourse(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := database.DB.Save(&course).Error; err != nil {
+	if err := database.DB.Omit(clause.Associations).Save(&course).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update course",
 		})
@@ -170,6 +197,17 @@ func DeleteCourse(c *fiber.Ctx) error {
 		})
 	}
 
+	var course models.Course
+	if err := database.DB.First(&course, courseID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Course not found",
+		})
+	}
+
+	if !inDepartment(c, course.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	result := database.DB.Delete(&models.Course{}, courseID)
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -188,15 +226,12 @@ func DeleteCourse(c *fiber.Ctx) error {
 	})
 }
 
-// GetCourseCategories returns list of unique course categories
+// GetCourseCategories returns the course categories
 func GetCourseCategories(c *fiber.Ctx) error {
-	var categories []string
-
-	err := database.DB.Model(&models.Course{}).
-		Distinct().
-		Pluck("category", &categories).Error
+	var categories []models.CourseCategory
 
-	if err != nil {
+	result := database.DB.Order("code ASC").Find(&categories)
+	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to fetch categories",
 		})
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestCourseRoutes(t *testing.T) {
+	app := newTestApp(t)
+	department := seedDepartment(t, "CS")
+
+	body := fiber.Map{
+		"code":           "CS101",
+		"name":           "Programming I",
+		"department_id":  department.ID,
+		"course_type":    "THEORY",
+		"credits":        3,
+		"hours_per_week": 3,
+	}
+	response := call(t, app, "POST", "/api/v1/courses", body, 201)
+	var course models.Course
+	decode(t, response.Data, &course)
+	if course.Department == nil || course.Department.Code != "CS" {
+		t.Fatalf("created course has department %+v, want CS", course.Department)
+	}
+	path := "/api/v1/courses/" + course.ID.String()
+
+	call(t, app, "POST", "/api/v1/courses", body, 409)
+
+	response = call(t, app, "GET", path, nil, 200)
+	var fetched models.Course
+	decode(t, response.Data, &fetched)
+	if fetched.ID != course.ID || fetched.Code != "CS101" {
+		t.Fatalf("fetched course %s %s, want %s CS101", fetched.ID, fetched.Code, course.ID)
+	}
+
+	// An id in the body does not retarget the update at another course
+	other := seedCourse(t, "CS102", "LAB")
+	response = call(t, app, "PUT", path, fiber.Map{
+		"id":      other.ID,
+		"name":    "Programming Fundamentals",
+		"credits": 4,
+	}, 200)
+	var updated models.Course
+	decode(t, response.Data, &updated)
+	if updated.ID != course.ID || updated.Name != "Programming Fundamentals" || updated.Credits != 4 {
+		t.Fatalf("updated course is %s %q with %d credits", updated.ID, updated.Name, updated.Credits)
+	}
+	var untouched models.Course
+	database.DB.First(&untouched, other.ID)
+	if untouched.Name != other.Name {
+		t.Fatalf("update renamed the other course to %q", untouched.Name)
+	}
+
+	call(t, app, "DELETE", path, nil, 200)
+	call(t, app, "GET", path, nil, 404)
+	call(t, app, "DELETE", path, nil, 404)
+}
+
+func TestGetCoursesFilters(t *testing.T) {
+	app := newTestApp(t)
+	seedCourse(t, "CS101", "THEORY")
+	seedCourse(t, "CS102", "LAB")
+	seedCourse(t, "CS103", "SEMINAR")
+
+	for _, test := range []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"CS101", "CS102", "CS103"}},
+		{"?is_lab=true", []string{"CS102"}},
+		{"?is_lab=false", []string{"CS101", "CS103"}},
+		{"?course_type=SEMINAR", []string{"CS103"}},
+	} {
+		response := call(t, app, "GET", "/api/v1/courses"+test.query, nil, 200)
+		var courses []models.Course
+		decode(t, response.Data, &courses)
+
+		codes := make([]string, 0, len(courses))
+		for _, course := range courses {
+			codes = append(codes, course.Code)
+		}
+		if !slices.Equal(codes, test.want) || response.Count != len(test.want) {
+			t.Errorf("GET /courses%s returned %v (count %d), want %v", test.query, codes, response.Count, test.want)
+		}
+	}
+}
+
+func TestGetCourseCategories(t *testing.T) {
+	app := newTestApp(t)
+	seed(t, &models.CourseCategory{Code: "SEC", Name: "Skill Enhancement Course"})
+	seed(t, &models.CourseCategory{Code: "MAJOR", Name: "Major"})
+
+	response := call(t, app, "GET", "/api/v1/courses/categories", nil, 200)
+	var categories []models.CourseCategory
+	decode(t, response.Data, &categories)
+
+	codes := make([]string, 0, len(categories))
+	for _, category := range categories {
+		codes = append(codes, category.Code)
+	}
+	if want := []string{"MAJOR", "SEC"}; !slices.Equal(codes, want) {
+		t.Fatalf("categories are %v, want %v", codes, want)
+	}
+}
@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+	"github.com/yourusername/timetable-scheduler/internal/optimization"
+)
+
+// Kinds of change between two schedules
+const (
+	ChangeAdded      = "ADDED"      // A meeting only the newer schedule has
+	ChangeRemoved    = "REMOVED"    // A meeting only the older schedule has
+	ChangeMoved      = "MOVED"      // A meeting held at another time
+	ChangeReassigned = "REASSIGNED" // A meeting at the same time in another room or with another faculty member
+)
+
+// ClassChange is one meeting that differs between two schedules. Before and
+// After are the meeting in the older and newer schedule; an added meeting has
+// no Before and a removed one no After.
+type ClassChange struct {
+	Type           string               `json:"type"`
+	CourseID       uuid.UUID            `json:"course_id"`
+	CourseCode     string               `json:"course_code"`
+	CourseName     string               `json:"course_name"`
+	RoomChanged    bool                 `json:"room_changed"`
+	FacultyChanged bool                 `json:"faculty_changed"`
+	Before         *models.VersionClass `json:"before,omitempty"`
+	After          *models.VersionClass `json:"after,omitempty"`
+	StudentGroups  []string             `json:"student_groups"`
+}
+
+// DiffGroup collects the changes that affect one faculty member, room or
+// student group
+type DiffGroup struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Changes []ClassChange `json:"changes"`
+}
+
+// DiffSummary counts the changes by kind. RoomChanges and FacultyChanges
+// count moved and reassigned meetings whose room or faculty member changed.
+type DiffSummary struct {
+	Added          int `json:"added"`
+	Removed        int `json:"removed"`
+	Moved          int `json:"moved"`
+	Reassigned     int `json:"reassigned"`
+	RoomChanges    int `json:"room_changes"`
+	FacultyChanges int `json:"faculty_changes"`
+	Unchanged      int `json:"unchanged"`
+}
+
+// TimetableDiff is what changed from one schedule to another, as a flat list
+// and grouped by who and what each change affects, so it can be turned into
+// notifications and change reports directly
+type TimetableDiff struct {
+	Summary        DiffSummary   `json:"summary"`
+	Changes        []ClassChange `json:"changes"`
+	ByFaculty      []DiffGroup   `json:"by_faculty"`
+	ByRoom         []DiffGroup   `json:"by_room"`
+	ByStudentGroup []DiffGroup   `json:"by_student_group"`
+}
+
+// meetingKey identifies the meetings of one course section: a lab batch, the
+// tutorials or the lectures. Meetings are only matched within a section.
+type meetingKey struct {
+	courseID uuid.UUID
+	lab      bool
+	tutorial bool
+	batch    int
+}
+
+func keyOf(class models.VersionClass) meetingKey {
+	key := meetingKey{courseID: class.CourseID, lab: class.IsLab, tutorial: class.IsTutorial}
+	if class.BatchNumber != nil {
+		key.batch = *class.BatchNumber
+	}
+	return key
+}
+
+// buildTimetableDiff compares two schedules. Meetings of the same course
+// section are matched first where nothing changed, then where only the room
+// or faculty member changed, then in day and time order as moves; whatever is
+// left was added or removed. Student groups are the cohorts taking each
+// course, or the course's own students when no cohort does.
+func buildTimetableDiff(before, after []models.VersionClass, cohorts []optimization.CourseCohort) TimetableDiff {
+	diff := TimetableDiff{Changes: []ClassChange{}}
+
+	sections := make(map[meetingKey]*[2][]models.VersionClass)
+	var order []meetingKey
+	for side, classes := range [2][]models.VersionClass{before, after} {
+		for _, class := range classes {
+			key := keyOf(class)
+			if sections[key] == nil {
+				sections[key] = &[2][]models.VersionClass{}
+				order = append(order, key)
+			}
+			sections[key][side] = append(sections[key][side], class)
+		}
+	}
+
+	for _, key := range order {
+		olds, news := sections[key][0], sections[key][1]
+		sortMeetings(olds)
+		sortMeetings(news)
+		matched := make([]bool, len(news))
+
+		// pair matches each unmatched old meeting to the first unmatched new
+		// one the test accepts, returning the old meetings left over
+		pair := func(olds []models.VersionClass, accept func(a, b models.VersionClass) bool, matchedPair func(a, b models.VersionClass)) []models.VersionClass {
+			var left []models.VersionClass
+			for _, old := range olds {
+				found := false
+				for i, class := range news {
+					if !matched[i] && accept(old, class) {
+						matched[i] = true
+						matchedPair(old, class)
+						found = true
+						break
+					}
+				}
+				if !found {
+					left = append(left, old)
+				}
+			}
+			return left
+		}
+
+		olds = pair(olds, func(a, b models.VersionClass) bool {
+			return sameSlot(a, b) && sameID(a.RoomID, b.RoomID) && sameID(a.FacultyID, b.FacultyID)
+		}, func(a, b models.VersionClass) {
+			diff.Summary.Unchanged++
+		})
+		olds = pair(olds, sameSlot, func(a, b models.VersionClass) {
+			diff.Changes = append(diff.Changes, newChange(ChangeReassigned, a, b))
+		})
+		olds = pair(olds, func(a, b models.VersionClass) bool { return true }, func(a, b models.VersionClass) {
+			diff.Changes = append(diff.Changes, newChange(ChangeMoved, a, b))
+		})
+
+		for _, old := range olds {
+			old := old
+			diff.Changes = append(diff.Changes, ClassChange{
+				Type:       ChangeRemoved,
+				CourseID:   old.CourseID,
+				CourseCode: old.CourseCode,
+				CourseName: old.CourseName,
+				Before:     &old,
+			})
+		}
+		for i, class := range news {
+			if matched[i] {
+				continue
+			}
+			class := class
+			diff.Changes = append(diff.Changes, ClassChange{
+				Type:       ChangeAdded,
+				CourseID:   class.CourseID,
+				CourseCode: class.CourseCode,
+				CourseName: class.CourseName,
+				After:      &class,
+			})
+		}
+	}
+
+	groupsOf := make(map[uuid.UUID][]string)
+	for _, cohort := range cohorts {
+		for _, courseID := range cohort.CourseIDs {
+			groupsOf[courseID] = append(groupsOf[courseID], cohort.GroupID)
+		}
+	}
+
+	for i := range diff.Changes {
+		change := &diff.Changes[i]
+		change.StudentGroups = groupsOf[change.CourseID]
+		if len(change.StudentGroups) == 0 {
+			change.StudentGroups = []string{change.CourseCode}
+		}
+
+		switch change.Type {
+		case ChangeAdded:
+			diff.Summary.Added++
+		case ChangeRemoved:
+			diff.Summary.Removed++
+		case ChangeMoved:
+			diff.Summary.Moved++
+		case ChangeReassigned:
+			diff.Summary.Reassigned++
+		}
+		if change.RoomChanged {
+			diff.Summary.RoomChanges++
+		}
+		if change.FacultyChanged {
+			diff.Summary.FacultyChanges++
+		}
+	}
+
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i].first(), diff.Changes[j].first()
+		if a.DayOfWeek != b.DayOfWeek {
+			return a.DayOfWeek < b.DayOfWeek
+		}
+		if a.StartTime != b.StartTime {
+			return a.StartTime < b.StartTime
+		}
+		return a.CourseCode < b.CourseCode
+	})
+
+	diff.ByFaculty = groupChanges(diff.Changes, func(change ClassChange) []DiffGroup {
+		var groups []DiffGroup
+		for _, class := range change.sides() {
+			if class.FacultyID != nil {
+				groups = append(groups, DiffGroup{ID: class.FacultyID.String(), Name: class.FacultyName})
+			}
+		}
+		return groups
+	})
+	diff.ByRoom = groupChanges(diff.Changes, func(change ClassChange) []DiffGroup {
+		var groups []DiffGroup
+		for _, class := range change.sides() {
+			if class.RoomID != nil {
+				groups = append(groups, DiffGroup{ID: class.RoomID.String(), Name: class.RoomNumber})
+			}
+		}
+		return groups
+	})
+	diff.ByStudentGroup = groupChanges(diff.Changes, func(change ClassChange) []DiffGroup {
+		var groups []DiffGroup
+		for _, group := range change.StudentGroups {
+			groups = append(groups, DiffGroup{ID: group, Name: group})
+		}
+		return groups
+	})
+
+	return diff
+}
+
+func newChange(kind string, before, after models.VersionClass) ClassChange {
+	return ClassChange{
+		Type:           kind,
+		CourseID:       after.CourseID,
+		CourseCode:     after.CourseCode,
+		CourseName:     after.CourseName,
+		RoomChanged:    !sameID(before.RoomID, after.RoomID),
+		FacultyChanged: !sameID(before.FacultyID, after.FacultyID),
+		Before:         &before,
+		After:          &after,
+	}
+}
+
+// first is the meeting a change is listed under: where it was, or where it
+// is for an added one
+func (change ClassChange) first() models.VersionClass {
+	if change.Before != nil {
+		return *change.Before
+	}
+	return *change.After
+}
+
+// sides returns the meeting before and after the change, whichever exist
+func (change ClassChange) sides() []models.VersionClass {
+	var classes []models.VersionClass
+	if change.Before != nil {
+		classes = append(classes, *change.Before)
+	}
+	if change.After != nil {
+		classes = append(classes, *change.After)
+	}
+	return classes
+}
+
+// groupChanges files each change under the groups it affects, once per group,
+// and orders the groups by name
+func groupChanges(changes []ClassChange, groupsOf func(change ClassChange) []DiffGroup) []DiffGroup {
+	groups := []DiffGroup{}
+	index := make(map[string]int)
+	for _, change := range changes {
+		filed := make(map[string]bool)
+		for _, group := range groupsOf(change) {
+			if filed[group.ID] {
+				continue
+			}
+			filed[group.ID] = true
+
+			i, ok := index[group.ID]
+			if !ok {
+				i = len(groups)
+				index[group.ID] = i
+				groups = append(groups, group)
+			}
+			groups[i].Changes = append(groups[i].Changes, change)
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+func sortMeetings(classes []models.VersionClass) {
+	sort.SliceStable(classes, func(i, j int) bool {
+		if classes[i].DayOfWeek != classes[j].DayOfWeek {
+			return classes[i].DayOfWeek < classes[j].DayOfWeek
+		}
+		return classes[i].StartTime < classes[j].StartTime
+	})
+}
+
+func sameSlot(a, b models.VersionClass) bool {
+	return a.DayOfWeek == b.DayOfWeek && a.StartTime == b.StartTime && a.EndTime == b.EndTime
+}
+
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// parseAgainst reads what a diff compares against: a timetable ID, or a
+// version number with or without a "v" prefix
+func parseAgainst(against string) (timetableID uuid.UUID, version int, ok bool) {
+	if id, err := uuid.Parse(against); err == nil && id != uuid.Nil {
+		return id, 0, true
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(against, "v"))
+	if err != nil || number < 1 {
+		return uuid.Nil, 0, false
+	}
+	return uuid.Nil, number, true
+}
+
+// GetTimetableDiff compares the timetable's schedule with another timetable's,
+// given by ID in the against query parameter, or with one of its own
+// published versions, given by number ("3" or "v3"). Changes run from the
+// compared schedule to this one.
+func GetTimetableDiff(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	timetableID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	var timetable models.TimetableTemplate
+	if err := database.DB.First(&timetable, timetableID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Timetable not found",
+		})
+	}
+
+	against := c.Query("against")
+	otherID, number, ok := parseAgainst(against)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "against must be a timetable ID or a version number",
+		})
+	}
+
+	timetables := []models.TimetableTemplate{timetable}
+	var before []models.VersionClass
+
+	if otherID != uuid.Nil {
+		var other models.TimetableTemplate
+		if err := database.DB.First(&other, otherID).Error; err != nil || !inProgram(c, other.ProgramID) {
+			return c.Status(404).JSON(fiber.Map{
+				"error": "Timetable to compare against not found",
+			})
+		}
+		if before, err = snapshotClasses(database.DB, other.ID); err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error": "Failed to fetch scheduled classes",
+			})
+		}
+		timetables = append(timetables, other)
+	} else {
+		var version models.TimetableVersion
+		if err := database.DB.Where("timetable_id = ? AND version = ?", timetableID, number).First(&version).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error": "Version not found",
+			})
+		}
+		before = version.Classes
+	}
+
+	after, err := snapshotClasses(database.DB, timetableID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to fetch scheduled classes",
+		})
+	}
+
+	// Student groups come from the curriculum cohorts of both sides
+	courseIDs := make([]uuid.UUID, 0, len(before)+len(after))
+	for _, class := range append(append([]models.VersionClass{}, before...), after...) {
+		courseIDs = append(courseIDs, class.CourseID)
+	}
+	var courses []models.Course
+	if len(courseIDs) > 0 {
+		database.DB.Preload("Category").Where("id IN ?", courseIDs).Find(&courses)
+	}
+	var cohorts []optimization.CourseCohort
+	for _, t := range timetables {
+		cohorts = append(cohorts, getCurriculumCohorts(t, courses)...)
+	}
+
+	return c.JSON(fiber.Map{
+		"against": against,
+		"data":    buildTimetableDiff(before, after, cohorts),
+	})
+}
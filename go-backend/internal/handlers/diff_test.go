@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+	"github.com/yourusername/timetable-scheduler/internal/optimization"
+)
+
+// Fixtures of the diff tests
+var (
+	diffCourses = map[string]uuid.UUID{
+		"CS101": uuid.MustParse("00000000-0000-0000-0000-000000000101"),
+		"CS102": uuid.MustParse("00000000-0000-0000-0000-000000000102"),
+		"CS103": uuid.MustParse("00000000-0000-0000-0000-000000000103"),
+	}
+	diffFaculty = map[string]uuid.UUID{
+		"Ada":   uuid.MustParse("00000000-0000-0000-0000-00000000f001"),
+		"Grace": uuid.MustParse("00000000-0000-0000-0000-00000000f002"),
+	}
+	diffRooms = map[string]uuid.UUID{
+		"A101": uuid.MustParse("00000000-0000-0000-0000-00000000a101"),
+		"B202": uuid.MustParse("00000000-0000-0000-0000-00000000b202"),
+	}
+)
+
+// meeting is an hour-long lecture of a course
+func meeting(course string, day int, start, faculty, room string) models.VersionClass {
+	facultyID, roomID := diffFaculty[faculty], diffRooms[room]
+	hour, _ := strconv.Atoi(start[:2])
+	return models.VersionClass{
+		CourseID:    diffCourses[course],
+		CourseCode:  course,
+		FacultyID:   &facultyID,
+		FacultyName: faculty,
+		RoomID:      &roomID,
+		RoomNumber:  room,
+		DayOfWeek:   day,
+		StartTime:   start,
+		EndTime:     fmt.Sprintf("%02d:00", hour+1),
+	}
+}
+
+// describeChange writes a change as its kind, course and what changed, such
+// as "MOVED CS101 room"
+func describeChange(change ClassChange) string {
+	description := change.Type + " " + change.CourseCode
+	if change.RoomChanged {
+		description += " room"
+	}
+	if change.FacultyChanged {
+		description += " faculty"
+	}
+	return description
+}
+
+func TestBuildTimetableDiff(t *testing.T) {
+	lab := func(class models.VersionClass) models.VersionClass {
+		class.IsLab = true
+		return class
+	}
+
+	tests := []struct {
+		name          string
+		before, after []models.VersionClass
+		want          []string
+		summary       DiffSummary
+	}{
+		{
+			name:    "unchanged",
+			before:  []models.VersionClass{meeting("CS101", 1, "09:00", "Ada", "A101")},
+			after:   []models.VersionClass{meeting("CS101", 1, "09:00", "Ada", "A101")},
+			want:    []string{},
+			summary: DiffSummary{Unchanged: 1},
+		},
+		{
+			name:    "added",
+			after:   []models.VersionClass{meeting("CS101", 1, "09:00", "Ada", "A101")},
+			want:    []string{"ADDED CS101"},
+			summary: DiffSummary{Added: 1},
+		},
+		{
+			name:    "removed",
+			before:  []models.VersionClass{meeting("CS101", 1, "09:00", "Ada", "A101")},
+			want:    []string{"REMOVED CS101"},
+			summary: DiffSummary{Removed: 1},
+		},
+		{
+			name:    "moved to another day",
+			before:  []models.VersionClass{meeting("CS101", 1, "09:00", "Ada", "A101")},
+			after:   []models.VersionClass{meeting("CS101", 2, "09:00", "Ada", "A101")},
+			want:    []string{"MOVED CS101"},
+			summary: DiffSummary{Moved: 1},
+		},
+		{
+			name:    "moved to another time and room",
+			before:  []models.VersionClass{meeting("CS101", 1, "09:00", "Ada", "A101")},
+			after:   []models.VersionClass{meeting("CS101", 1, "11:00", "Ada", "B202")},
+			want:    []string{"MOVED CS101 room"},
+			summary: DiffSummary{Moved: 1, RoomChanges: 1},
+		},
+		{
+			name:    "another room at the same time",
+			before:  []models.VersionClass{meeting("CS101", 1, "09:00", "Ada", "A101")},
+			after:   []models.VersionClass{meeting("CS101", 1, "09:00", "Ada", "B202")},
+			want:    []string{"REASSIGNED CS101 room"},
+			summary: DiffSummary{Reassigned: 1, RoomChanges: 1},
+		},
+		{
+			name:    "another faculty member",
+			before:  []models.VersionClass{meeting("CS101", 1, "09:00", "Ada", "A101")},
+			after:   []models.VersionClass{meeting("CS101", 1, "09:00", "Grace", "A101")},
+			want:    []string{"REASSIGNED CS101 faculty"},
+			summary: DiffSummary{Reassigned: 1, FacultyChanges: 1},
+		},
+		{
+			name: "meetings that stay put are matched before moves",
+			before: []models.VersionClass{
+				meeting("CS101", 1, "09:00", "Ada", "A101"),
+				meeting("CS101", 3, "09:00", "Ada", "A101"),
+			},
+			after: []models.VersionClass{
+				meeting("CS101", 5, "09:00", "Ada", "A101"),
+				meeting("CS101", 3, "09:00", "Ada", "A101"),
+			},
+			want:    []string{"MOVED CS101"},
+			summary: DiffSummary{Moved: 1, Unchanged: 1},
+		},
+		{
+			name:    "a lecture does not become a lab",
+			before:  []models.VersionClass{meeting("CS101", 1, "09:00", "Ada", "A101")},
+			after:   []models.VersionClass{lab(meeting("CS101", 1, "09:00", "Ada", "A101"))},
+			want:    []string{"REMOVED CS101", "ADDED CS101"},
+			summary: DiffSummary{Added: 1, Removed: 1},
+		},
+		{
+			name: "changes are ordered by where they were",
+			before: []models.VersionClass{
+				meeting("CS102", 2, "09:00", "Ada", "A101"),
+				meeting("CS101", 4, "09:00", "Ada", "A101"),
+			},
+			after: []models.VersionClass{
+				meeting("CS102", 1, "09:00", "Ada", "A101"),
+				meeting("CS101", 1, "11:00", "Ada", "A101"),
+				meeting("CS103", 3, "09:00", "Ada", "A101"),
+			},
+			want:    []string{"MOVED CS102", "ADDED CS103", "MOVED CS101"},
+			summary: DiffSummary{Added: 1, Moved: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff := buildTimetableDiff(test.before, test.after, nil)
+
+			got := make([]string, 0, len(diff.Changes))
+			for _, change := range diff.Changes {
+				got = append(got, describeChange(change))
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("changes are %v, want %v", got, test.want)
+			}
+			if diff.Summary != test.summary {
+				t.Errorf("summary is %+v, want %+v", diff.Summary, test.summary)
+			}
+		})
+	}
+}
+
+func TestBuildTimetableDiffGroups(t *testing.T) {
+	before := []models.VersionClass{
+		meeting("CS101", 1, "09:00", "Ada", "A101"),
+		meeting("CS102", 2, "09:00", "Ada", "A101"),
+	}
+	after := []models.VersionClass{
+		meeting("CS101", 1, "09:00", "Grace", "A101"),
+		meeting("CS102", 2, "11:00", "Ada", "B202"),
+		meeting("CS103", 3, "09:00", "Grace", "B202"),
+	}
+	cohorts := []optimization.CourseCohort{
+		{GroupID: "BSC-CS-1", CourseIDs: []uuid.UUID{diffCourses["CS101"], diffCourses["CS102"]}},
+		{GroupID: "BSC-MATH-1", CourseIDs: []uuid.UUID{diffCourses["CS102"]}},
+	}
+
+	diff := buildTimetableDiff(before, after, cohorts)
+
+	// Each group lists the courses of its changes; a change touching both
+	// sides of a group is filed there once
+	describe := func(groups []DiffGroup) []string {
+		described := make([]string, 0, len(groups))
+		for _, group := range groups {
+			courses := make([]string, 0, len(group.Changes))
+			for _, change := range group.Changes {
+				courses = append(courses, change.CourseCode)
+			}
+			described = append(described, group.Name+": "+strings.Join(courses, " "))
+		}
+		return described
+	}
+
+	for _, test := range []struct {
+		by     string
+		groups []DiffGroup
+		want   []string
+	}{
+		{"faculty", diff.ByFaculty, []string{"Ada: CS101 CS102", "Grace: CS101 CS103"}},
+		{"room", diff.ByRoom, []string{"A101: CS101 CS102", "B202: CS102 CS103"}},
+		// A course without a cohort is its own group
+		{"student group", diff.ByStudentGroup, []string{"BSC-CS-1: CS101 CS102", "BSC-MATH-1: CS102", "CS103: CS103"}},
+	} {
+		if got := describe(test.groups); !slices.Equal(got, test.want) {
+			t.Errorf("changes by %s are %v, want %v", test.by, got, test.want)
+		}
+	}
+
+	if groups := diff.ByFaculty[0].Changes[0].StudentGroups; !slices.Equal(groups, []string{"BSC-CS-1"}) {
+		t.Errorf("CS101 change affects %v, want [BSC-CS-1]", groups)
+	}
+}
+
+func TestParseAgainst(t *testing.T) {
+	id := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+
+	for _, test := range []struct {
+		against     string
+		timetableID uuid.UUID
+		version     int
+		ok          bool
+	}{
+		{id.String(), id, 0, true},
+		{"3", uuid.Nil, 3, true},
+		{"v3", uuid.Nil, 3, true},
+		{"v12", uuid.Nil, 12, true},
+		{"", uuid.Nil, 0, false},
+		{"v", uuid.Nil, 0, false},
+		{"0", uuid.Nil, 0, false},
+		{"v-1", uuid.Nil, 0, false},
+		{"V3", uuid.Nil, 0, false},
+		{"vv3", uuid.Nil, 0, false},
+		{"latest", uuid.Nil, 0, false},
+		{uuid.Nil.String(), uuid.Nil, 0, false},
+	} {
+		timetableID, version, ok := parseAgainst(test.against)
+		if timetableID != test.timetableID || version != test.version || ok != test.ok {
+			t.Errorf("parseAgainst(%q) = %s, %d, %v; want %s, %d, %v",
+				test.against, timetableID, version, ok, test.timetableID, test.version, test.ok)
+		}
+	}
+}
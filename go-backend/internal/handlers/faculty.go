@@ -4,14 +4,16 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/middleware"
 	"github.com/yourusername/timetable-scheduler/internal/models"
+	"gorm.io/gorm/clause"
 )
 
 // GetFaculty retrieves all faculty members
 func GetFaculty(c *fiber.Ctx) error {
 	var faculty []models.Faculty
 
-	query := database.DB.Preload("Department").Order("last_name ASC, first_name ASC")
+	query := scopeByDepartment(c, database.DB).Preload("Department").Order("last_name ASC, first_name ASC")
 
 	// Filter by department if provided
 	if deptID := c.Query("department_id"); deptID != "" {
@@ -43,11 +45,11 @@ func GetFacultyMember(c *fiber.Ctx) error {
 	}
 
 	var faculty models.Faculty
-	result := database.DB.
+	result := scopeByDepartment(c, database.DB).
 		Preload("Department").
 		Preload("Availability").
-		Preload("Expertise").
-		Preload("Expertise.Course").
+		Preload("CourseExpertise").
+		Preload("CourseExpertise.Course").
 		First(&faculty, facultyID)
 
 	if result.Error != nil {
@@ -84,6 +86,10 @@ func CreateFaculty(c *fiber.Ctx) error {
 		})
 	}
 
+	if !inDepartment(c, faculty.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	// Check for duplicate employee ID
 	var existing models.Faculty
 	if err := database.DB.Where("employee_id = ?", faculty.EmployeeID).First(&existing).Error; err == nil {
@@ -133,16 +139,29 @@ func UpdateFaculty(c *fiber.Ctx) error {
 		})
 	}
 
+	if !inDepartment(c, faculty.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	// Store originals to check for duplicates
 	originalEmployeeID := faculty.EmployeeID
 	originalEmail := faculty.Email
 
+	// The body may not retarget the update at another row
+	base := faculty.Base
 	if err := c.BodyParser(&faculty); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
+	faculty.Base = base
+
+	// The faculty member may not be moved out of the caller's department either
+	if !inDepartment(c, faculty.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	// Check for duplicate employee ID if changed
 	if faculty.EmployeeID != originalEmployeeID {
 		var existing models.Faculty
@@ -163,7 +182,7 @@ func UpdateFaculty(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := database.DB.Save(&faculty).Error; err != nil {
+	if err := database.DB.Omit(clause.Associations).Save(&faculty).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update faculty member",
 		})
@@ -189,6 +208,17 @@ func DeleteFaculty(c *fiber.Ctx) error {
 		})
 	}
 
+	var faculty models.Faculty
+	if err := database.DB.First(&faculty, facultyID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Faculty member not found",
+		})
+	}
+
+	if !inDepartment(c, faculty.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	result := database.DB.Delete(&models.Faculty{}, facultyID)
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -218,6 +248,14 @@ func GetFacultyAvailability(c *fiber.Ctx) error {
 		})
 	}
 
+	// Faculty outside the caller's department read as not found
+	var faculty models.Faculty
+	if err := scopeByDepartment(c, database.DB).First(&faculty, facultyID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Faculty member not found",
+		})
+	}
+
 	var availability []models.FacultyAvailability
 	result := database.DB.Where("faculty_id = ?", facultyID).Find(&availability)
 	if result.Error != nil {
@@ -251,6 +289,16 @@ func AddFacultyAvailability(c *fiber.Ctx) error {
 		})
 	}
 
+	if !canManageAvailability(c, faculty) {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Faculty can only change their own availability",
+		})
+	}
+
+	if !inDepartment(c, faculty.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	var availability models.FacultyAvailability
 	if err := c.BodyParser(&availability); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -280,6 +328,71 @@ func AddFacultyAvailability(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateFacultyAvailability updates an availability slot
+func UpdateFacultyAvailability(c *fiber.Ctx) error {
+	facultyID := c.Params("id")
+	availabilityID := c.Params("availability_id")
+
+	availID, err := uuid.Parse(availabilityID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid availability ID format",
+		})
+	}
+
+	var faculty models.Faculty
+	if err := database.DB.Where("id = ?", facultyID).First(&faculty).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Faculty member not found",
+		})
+	}
+
+	if !canManageAvailability(c, faculty) {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Faculty can only change their own availability",
+		})
+	}
+
+	if !inDepartment(c, faculty.DepartmentID) {
+		return outOfScope(c)
+	}
+
+	var availability models.FacultyAvailability
+	if err := database.DB.Where("id = ? AND faculty_id = ?", availID, faculty.ID).First(&availability).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Availability slot not found",
+		})
+	}
+
+	// The body may not move the slot to another row or faculty member
+	if err := c.BodyParser(&availability); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	availability.ID = availID
+	availability.FacultyID = faculty.ID
+
+	// Validation
+	if availability.DayOfWeek < 0 || availability.DayOfWeek > 6 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Day of week must be between 0 (Sunday) and 6 (Saturday)",
+		})
+	}
+
+	if err := database.DB.Omit(clause.Associations).Save(&availability).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to update availability",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Availability updated successfully",
+		"data":    availability,
+	})
+}
+
 // DeleteFacultyAvailability deletes an availability slot
 func DeleteFacultyAvailability(c *fiber.Ctx) error {
 	facultyID := c.Params("id")
@@ -292,6 +405,23 @@ func DeleteFacultyAvailability(c *fiber.Ctx) error {
 		})
 	}
 
+	var faculty models.Faculty
+	if err := database.DB.Where("id = ?", facultyID).First(&faculty).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Faculty member not found",
+		})
+	}
+
+	if !canManageAvailability(c, faculty) {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Faculty can only change their own availability",
+		})
+	}
+
+	if !inDepartment(c, faculty.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	result := database.DB.Where("id = ? AND faculty_id = ?", availID, facultyID).Delete(&models.FacultyAvailability{})
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -310,6 +440,15 @@ func DeleteFacultyAvailability(c *fiber.Ctx) error {
 	})
 }
 
+// canManageAvailability reports whether the current user may change the
+// faculty member's availability. Faculty may only change their own.
+func canManageAvailability(c *fiber.Ctx, faculty models.Faculty) bool {
+	if middleware.Role(c) != middleware.RoleFaculty {
+		return true
+	}
+	return faculty.UserID != nil && *faculty.UserID == middleware.UserID(c)
+}
+
 // GetFacultyExpertise retrieves expertise for a faculty member
 func GetFacultyExpertise(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -321,6 +460,14 @@ func GetFacultyExpertise(c *fiber.Ctx) error {
 		})
 	}
 
+	// Faculty outside the caller's department read as not found
+	var faculty models.Faculty
+	if err := scopeByDepartment(c, database.DB).First(&faculty, facultyID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Faculty member not found",
+		})
+	}
+
 	var expertise []models.FacultyCourseExpertise
 	result := database.DB.Preload("Course").Where("faculty_id = ?", facultyID).Find(&expertise)
 	if result.Error != nil {
@@ -354,6 +501,10 @@ func AddFacultyExpertise(c *fiber.Ctx) error {
 		})
 	}
 
+	if !inDepartment(c, faculty.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	var expertise models.FacultyCourseExpertise
 	if err := c.BodyParser(&expertise); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -407,6 +558,17 @@ func DeleteFacultyExpertise(c *fiber.Ctx) error {
 		})
 	}
 
+	var faculty models.Faculty
+	if err := database.DB.Where("id = ?", facultyID).First(&faculty).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Faculty member not found",
+		})
+	}
+
+	if !inDepartment(c, faculty.DepartmentID) {
+		return outOfScope(c)
+	}
+
 	result := database.DB.Where("id = ? AND faculty_id = ?", expID, facultyID).Delete(&models.FacultyCourseExpertise{})
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
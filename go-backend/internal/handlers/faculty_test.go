@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestFacultyRoutes(t *testing.T) {
+	app := newTestApp(t)
+	department := seedDepartment(t, "CS")
+	course := seedCourse(t, "CS101", "THEORY")
+
+	body := fiber.Map{
+		"employee_id":   "EMP001",
+		"first_name":    "Ada",
+		"last_name":     "Lovelace",
+		"email":         "ada@example.edu",
+		"designation":   "Professor",
+		"department_id": department.ID,
+	}
+	response := call(t, app, "POST", "/api/v1/faculty", body, 201)
+	var faculty models.Faculty
+	decode(t, response.Data, &faculty)
+	path := "/api/v1/faculty/" + faculty.ID.String()
+
+	call(t, app, "POST", "/api/v1/faculty", body, 409)
+
+	call(t, app, "POST", path+"/expertise", fiber.Map{
+		"course_id":        course.ID,
+		"preference_level": 5,
+	}, 201)
+
+	// The detail includes the course expertise with its courses
+	response = call(t, app, "GET", path, nil, 200)
+	var fetched models.Faculty
+	decode(t, response.Data, &fetched)
+	if fetched.Department == nil || fetched.Department.Code != "CS" {
+		t.Fatalf("fetched faculty member has department %+v, want CS", fetched.Department)
+	}
+	if len(fetched.CourseExpertise) != 1 || fetched.CourseExpertise[0].Course.Code != "CS101" {
+		t.Fatalf("fetched faculty member has expertise %+v, want CS101", fetched.CourseExpertise)
+	}
+
+	response = call(t, app, "PUT", path, fiber.Map{"designation": "Associate Professor"}, 200)
+	var updated models.Faculty
+	decode(t, response.Data, &updated)
+	if updated.ID != faculty.ID || updated.Designation != "Associate Professor" || updated.EmployeeID != "EMP001" {
+		t.Fatalf("updated faculty member is %s %s %q", updated.ID, updated.EmployeeID, updated.Designation)
+	}
+
+	call(t, app, "DELETE", path, nil, 200)
+	call(t, app, "GET", path, nil, 404)
+	call(t, app, "DELETE", path, nil, 404)
+}
+
+func TestFacultyAvailabilityRoutes(t *testing.T) {
+	app := newTestApp(t)
+	faculty := seedFaculty(t, "EMP001")
+	path := "/api/v1/faculty/" + faculty.ID.String() + "/availability"
+
+	call(t, app, "POST", path, fiber.Map{"day_of_week": 7, "start_time": "09:00", "end_time": "12:00"}, 400)
+
+	response := call(t, app, "POST", path, fiber.Map{"day_of_week": 1, "start_time": "09:00", "end_time": "12:00"}, 201)
+	var slot models.FacultyAvailability
+	decode(t, response.Data, &slot)
+	slotPath := path + "/" + slot.ID.String()
+	call(t, app, "POST", path, fiber.Map{"day_of_week": 3, "start_time": "14:00", "end_time": "17:00"}, 201)
+
+	response = call(t, app, "GET", path, nil, 200)
+	if response.Count != 2 {
+		t.Fatalf("faculty member has %d availability slots, want 2", response.Count)
+	}
+
+	// An id or faculty_id in the body does not move the update elsewhere
+	other := seedFaculty(t, "EMP002")
+	response = call(t, app, "PUT", slotPath, fiber.Map{
+		"id":          uuid.New(),
+		"faculty_id":  other.ID,
+		"day_of_week": 2,
+		"end_time":    "13:00",
+	}, 200)
+	var updated models.FacultyAvailability
+	decode(t, response.Data, &updated)
+	if updated.ID != slot.ID || updated.FacultyID != faculty.ID || updated.DayOfWeek != 2 || updated.EndTime != "13:00" {
+		t.Fatalf("updated slot is %+v", updated)
+	}
+	call(t, app, "PUT", slotPath, fiber.Map{"day_of_week": -1}, 400)
+	call(t, app, "PUT", "/api/v1/faculty/"+other.ID.String()+"/availability/"+slot.ID.String(), fiber.Map{"day_of_week": 4}, 404)
+
+	call(t, app, "GET", "/api/v1/faculty/not-a-uuid/availability", nil, 400)
+	call(t, app, "GET", "/api/v1/faculty/"+uuid.NewString()+"/availability", nil, 404)
+
+	call(t, app, "DELETE", slotPath, nil, 200)
+	call(t, app, "DELETE", slotPath, nil, 404)
+	call(t, app, "PUT", slotPath, fiber.Map{"day_of_week": 4}, 404)
+
+	response = call(t, app, "GET", path, nil, 200)
+	if response.Count != 1 {
+		t.Fatalf("faculty member has %d availability slots after the delete, want 1", response.Count)
+	}
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/jobs"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+	"github.com/yourusername/timetable-scheduler/internal/optimization"
+	"gorm.io/gorm"
+)
+
+// maxFitnessPoints caps the fitness curve stored with a generation run
+const maxFitnessPoints = 200
+
+// runRecorder follows a generation's progress updates and keeps the fitness
+// curve of the run
+type runRecorder struct {
+	mu      sync.Mutex
+	history []models.FitnessPoint
+}
+
+// record adds a point to the fitness curve whenever the best fitness or the
+// search phase changes. A curve that grows past maxFitnessPoints is thinned
+// to every other point, always keeping the latest one.
+func (r *runRecorder) record(progress optimization.Progress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n := len(r.history); n > 0 {
+		last := r.history[n-1]
+		if last.Phase == progress.Phase && last.BestFitness == progress.BestFitness {
+			return
+		}
+	}
+
+	r.history = append(r.history, models.FitnessPoint{
+		ElapsedMs:      progress.ElapsedMs,
+		Phase:          progress.Phase,
+		Iteration:      progress.Iteration,
+		CurrentFitness: progress.CurrentFitness,
+		BestFitness:    progress.BestFitness,
+	})
+
+	if len(r.history) > maxFitnessPoints {
+		thinned := make([]models.FitnessPoint, 0, maxFitnessPoints/2+1)
+		for i := 0; i < len(r.history)-1; i += 2 {
+			thinned = append(thinned, r.history[i])
+		}
+		r.history = append(thinned, r.history[len(r.history)-1])
+	}
+}
+
+// startGenerationRun records a run that is about to search with the given
+// engine settings and constraints
+func startGenerationRun(job *jobs.Job, timetableID uuid.UUID, config optimization.EngineConfig, seed int64, constraints []models.TimetableConstraint, userID uuid.UUID) (*models.GenerationRun, error) {
+	run := &models.GenerationRun{
+		TimetableID: timetableID,
+		JobID:       job.ID,
+		Status:      jobs.StatusRunning,
+		Algorithm:   config.Algorithm,
+		Config: map[string]interface{}{
+			"algorithm":          config.Algorithm,
+			"max_iterations":     config.MaxIterations,
+			"timeout_seconds":    config.Timeout.Seconds(),
+			"workers":            config.Workers,
+			"population_size":    config.PopulationSize,
+			"temperature":        config.Temperature,
+			"tabu_tenure":        config.TabuTenure,
+			"room_compatibility": config.RoomCompatibility,
+			"constraints":        constraints,
+		},
+		Seed:      seed,
+		StartedAt: time.Now(),
+	}
+	if userID != uuid.Nil {
+		run.CreatedBy = &userID
+	}
+
+	if err := database.DB.Create(run).Error; err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// finishGenerationRun fills in how the run ended, with the metrics and the
+// schedule of the best solution found when there is one
+func finishGenerationRun(tx *gorm.DB, run *models.GenerationRun, status string, runErr error, recorder *runRecorder, engine *optimization.TimetableEngine, solution *optimization.Solution) error {
+	now := time.Now()
+	run.Status = status
+	run.FinishedAt = &now
+	run.DurationMs = now.Sub(run.StartedAt).Milliseconds()
+	run.Iterations = engine.Iterations()
+	if runErr != nil {
+		run.Error = strPtr(runErr.Error())
+	}
+
+	recorder.mu.Lock()
+	run.FitnessHistory = recorder.history
+	recorder.mu.Unlock()
+
+	if solution != nil {
+		run.BestFitness = &solution.FitnessScore
+		run.HardViolations = solution.HardViolations
+		run.SoftViolations = solution.SoftViolations
+		run.ClassesScheduled = len(solution.Schedule)
+		run.PenaltyBreakdown = engine.PenaltyBreakdown(solution)
+		run.Assignments = runAssignments(solution)
+	}
+
+	return tx.Save(run).Error
+}
+
+// GetGenerationRuns lists a timetable's generation runs, newest first. The
+// fitness curve, penalty breakdown and schedule are left out; fetch a single
+// run for those.
+func GetGenerationRuns(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	timetableID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	query := database.DB.
+		Omit("fitness_history", "penalty_breakdown", "assignments").
+		Where("timetable_id = ?", timetableID).
+		Order("started_at DESC")
+
+	// Filter by status if provided
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var runs []models.GenerationRun
+	if err := query.Find(&runs).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to fetch generation runs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data":  runs,
+		"count": len(runs),
+	})
+}
+
+// GetGenerationRun retrieves one generation run with its fitness curve,
+// per-constraint penalties and schedule
+func GetGenerationRun(c *fiber.Ctx) error {
+	timetableID := c.Params("id")
+	runID, err := uuid.Parse(c.Params("run_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid run ID format",
+		})
+	}
+
+	var run models.GenerationRun
+	if err := database.DB.Where("id = ? AND timetable_id = ?", runID, timetableID).First(&run).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Generation run not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": run,
+	})
+}
+
+// PromoteGenerationRun replaces the timetable's scheduled classes with the
+// schedule a generation run found, as if that run had just finished. The
+// conflict logs are rebuilt against the current data and constraints.
+func PromoteGenerationRun(c *fiber.Ctx) error {
+	timetableID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	runID, err := uuid.Parse(c.Params("run_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid run ID format",
+		})
+	}
+
+	var run models.GenerationRun
+	if err := database.DB.Where("id = ? AND timetable_id = ?", runID, timetableID).First(&run).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Generation run not found",
+		})
+	}
+
+	if len(run.Assignments) == 0 {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Generation run has no schedule to promote",
+		})
+	}
+
+	if job, ok := generationJobs.Latest(timetableID); ok && job.Running() {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Timetable is being generated",
+		})
+	}
+
+	var timetable models.TimetableTemplate
+	if err := database.DB.First(&timetable, timetableID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Timetable not found",
+		})
+	}
+
+	solution, err := runSolution(run)
+	if err != nil {
+		return c.Status(409).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	engineConfig := optimizationConfig
+	engine, _, _, err := newGenerationEngine(timetable, &engineConfig)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	engine.Evaluate(solution)
+	violations := engine.Violations(solution)
+
+	// The run's courses, active or not, decide which classes are labs
+	courseIDs := make([]uuid.UUID, 0, len(run.Assignments))
+	for _, assignment := range run.Assignments {
+		courseIDs = append(courseIDs, assignment.CourseID)
+	}
+	var courses []models.Course
+	database.DB.Where("id IN ?", courseIDs).Find(&courses)
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveSolutionToDatabase(tx, timetable, courses, solution); err != nil {
+			return err
+		}
+
+		if err := saveConflictLogs(tx, timetableID, violations); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.TimetableTemplate{}).
+			Where("id = ?", timetableID).
+			Updates(map[string]interface{}{
+				"status":          generatedStatus(timetable.Status),
+				"algorithm_used":  run.Algorithm,
+				"generation_seed": run.Seed,
+			}).Error
+	})
+	if isForeignKeyViolation(err) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Generation run refers to courses, faculty or rooms that no longer exist",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to promote generation run",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Generation run promoted successfully",
+		"data": fiber.Map{
+			"run_id":            run.ID,
+			"hard_violations":   solution.HardViolations,
+			"soft_violations":   solution.SoftViolations,
+			"fitness_score":     solution.FitnessScore,
+			"classes_scheduled": len(solution.Schedule),
+			"violations":        violations,
+		},
+	})
+}
+
+// runAssignments lists the solution's meetings in key order, to be stored
+// with its generation run
+func runAssignments(solution *optimization.Solution) []models.RunAssignment {
+	keys := make([]string, 0, len(solution.Schedule))
+	for key := range solution.Schedule {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	assignments := make([]models.RunAssignment, 0, len(keys))
+	for _, key := range keys {
+		assignment := solution.Schedule[key]
+		assignments = append(assignments, models.RunAssignment{
+			Key:        key,
+			CourseID:   assignment.CourseID,
+			FacultyID:  assignment.FacultyID,
+			RoomID:     assignment.RoomID,
+			TimeSlotID: assignment.TimeSlot.ID,
+			DayOfWeek:  assignment.DayOfWeek,
+			StartTime:  assignment.StartTime,
+			EndTime:    assignment.EndTime,
+			EventIndex: assignment.EventIndex,
+			Length:     assignment.Length,
+		})
+	}
+	return assignments
+}
+
+// runSolution rebuilds the solution stored with a generation run. Every
+// meeting's first time slot must still exist.
+func runSolution(run models.GenerationRun) (*optimization.Solution, error) {
+	slotIDs := make([]uuid.UUID, 0, len(run.Assignments))
+	for _, assignment := range run.Assignments {
+		slotIDs = append(slotIDs, assignment.TimeSlotID)
+	}
+
+	var slots []models.TimeSlot
+	database.DB.Where("id IN ? AND timetable_id = ?", slotIDs, run.TimetableID).Find(&slots)
+	slotsByID := make(map[uuid.UUID]models.TimeSlot, len(slots))
+	for _, slot := range slots {
+		slotsByID[slot.ID] = slot
+	}
+
+	solution := &optimization.Solution{
+		Schedule: make(map[string]*optimization.ClassAssignment, len(run.Assignments)),
+	}
+	for _, assignment := range run.Assignments {
+		slot, ok := slotsByID[assignment.TimeSlotID]
+		if !ok {
+			return nil, errors.New("Generation run refers to time slots that no longer exist")
+		}
+
+		solution.Schedule[assignment.Key] = &optimization.ClassAssignment{
+			CourseID:   assignment.CourseID,
+			FacultyID:  assignment.FacultyID,
+			RoomID:     assignment.RoomID,
+			DayOfWeek:  assignment.DayOfWeek,
+			StartTime:  assignment.StartTime,
+			EndTime:    assignment.EndTime,
+			TimeSlot:   slot,
+			EventIndex: assignment.EventIndex,
+			Length:     assignment.Length,
+		}
+	}
+	return solution, nil
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/jobs"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestPromoteGenerationRun(t *testing.T) {
+	app := newTestApp(t)
+	semester := seedSemester(t)
+	timetable := createTimetable(t, app, semester.ID)
+	course := seedCourse(t, "CS101", "THEORY")
+	faculty := seedFaculty(t, "EMP001")
+	first := seedRoom(t, "A101", "CLASSROOM", 60)
+	second := seedRoom(t, "B202", "CLASSROOM", 60)
+	slot := timeSlot(t, timetable.ID, 1, "09:00")
+	path := "/api/v1/timetables/" + timetable.ID.String()
+
+	// seedRun records a completed run that put the course in a room
+	seedRun := func(roomID uuid.UUID) models.GenerationRun {
+		run := models.GenerationRun{
+			TimetableID: timetable.ID,
+			JobID:       uuid.New(),
+			Status:      jobs.StatusCompleted,
+			Algorithm:   "hybrid",
+			StartedAt:   time.Now(),
+		}
+		if roomID != uuid.Nil {
+			run.Assignments = []models.RunAssignment{{
+				Key:        course.ID.String() + "_0",
+				CourseID:   course.ID,
+				FacultyID:  faculty.ID,
+				RoomID:     roomID,
+				TimeSlotID: slot.ID,
+				DayOfWeek:  1,
+				StartTime:  "09:00",
+				EndTime:    "10:00",
+				Length:     1,
+			}}
+		}
+		seed(t, &run)
+		return run
+	}
+	promote := func(run models.GenerationRun, wantStatus int) {
+		t.Helper()
+		call(t, app, "POST", path+"/generation-runs/"+run.ID.String()+"/promote", nil, wantStatus)
+	}
+	// classRoom checks the timetable's status and the room of its one class
+	classRoom := func(wantStatus string, want models.Room) {
+		t.Helper()
+
+		var stored models.TimetableTemplate
+		database.DB.Preload("ScheduledClasses").First(&stored, timetable.ID)
+		if stored.Status != wantStatus {
+			t.Fatalf("timetable has status %s, want %s", stored.Status, wantStatus)
+		}
+		if len(stored.ScheduledClasses) != 1 || *stored.ScheduledClasses[0].RoomID != want.ID {
+			t.Fatalf("timetable has classes %+v, want one in %s", stored.ScheduledClasses, want.RoomNumber)
+		}
+	}
+
+	call(t, app, "POST", path+"/generation-runs/not-a-uuid/promote", nil, 400)
+	call(t, app, "POST", path+"/generation-runs/"+uuid.NewString()+"/promote", nil, 404)
+	promote(seedRun(uuid.Nil), 409)
+
+	firstRun := seedRun(first.ID)
+	secondRun := seedRun(second.ID)
+
+	promote(firstRun, 200)
+	classRoom("GENERATED", first)
+
+	// Whatever the engine finds wrong with this small schedule is accepted
+	database.DB.Model(&models.ConflictLog{}).Where("timetable_id = ?", timetable.ID).Update("is_resolved", true)
+	call(t, app, "POST", path+"/publish", nil, 200)
+
+	// Promoting into a published timetable starts a draft of its next
+	// version, leaving the published one in force
+	promote(secondRun, 200)
+	classRoom("DRAFT", second)
+
+	response := call(t, app, "GET", path+"/versions/in-force", nil, 200)
+	var version models.TimetableVersion
+	decode(t, response.Data, &version)
+	if version.Version != 1 || len(version.Classes) != 1 || version.Classes[0].RoomNumber != "A101" {
+		t.Fatalf("version %d is in force with classes %+v, want version 1 in A101", version.Version, version.Classes)
+	}
+
+	database.DB.Model(&models.ConflictLog{}).Where("timetable_id = ?", timetable.ID).Update("is_resolved", true)
+	response = call(t, app, "POST", path+"/publish", nil, 200)
+	if response.Version != 2 {
+		t.Fatalf("publishing the promoted run made version %d, want 2", response.Version)
+	}
+}
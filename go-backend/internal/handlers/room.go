@@ -5,6 +5,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/yourusername/timetable-scheduler/internal/database"
 	"github.com/yourusername/timetable-scheduler/internal/models"
+	"gorm.io/gorm/clause"
 )
 
 // GetRooms retrieves all rooms
@@ -18,9 +19,18 @@ func GetRooms(c *fiber.Ctx) error {
 		query = query.Where("building = ?", building)
 	}
 
-	// Filter by is_lab if provided
+	// Filter by room type if provided
+	if roomType := c.Query("room_type"); roomType != "" {
+		query = query.Where("room_type = ?", roomType)
+	}
+
+	// is_lab is shorthand for room type LAB
 	if isLab := c.Query("is_lab"); isLab != "" {
-		query = query.Where("is_lab = ?", isLab)
+		if c.QueryBool("is_lab") {
+			query = query.Where("room_type = ?", "LAB")
+		} else {
+			query = query.Where("room_type <> ?", "LAB")
+		}
 	}
 
 	// Filter by minimum capacity if provided
@@ -131,12 +141,16 @@ func UpdateRoom(c *fiber.Ctx) error {
 	originalRoomNumber := room.RoomNumber
 	originalBuilding := room.Building
 
+	// The body may not retarget the update at another row
+	base := room.Base
 	if err := c.BodyParser(&room); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
+	room.Base = base
+
 	// Check for duplicate room if room number or building changed
 	if room.RoomNumber != originalRoomNumber || room.Building != originalBuilding {
 		var existing models.Room
@@ -147,7 +161,7 @@ func UpdateRoom(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := database.DB.Save(&room).Error; err != nil {
+	if err := database.DB.Omit(clause.Associations).Save(&room).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update room",
 		})
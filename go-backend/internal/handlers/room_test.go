@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestRoomRoutes(t *testing.T) {
+	app := newTestApp(t)
+
+	body := fiber.Map{
+		"room_number": "A101",
+		"building":    "Main",
+		"room_type":   "CLASSROOM",
+		"capacity":    60,
+	}
+	response := call(t, app, "POST", "/api/v1/rooms", body, 201)
+	var room models.Room
+	decode(t, response.Data, &room)
+	path := "/api/v1/rooms/" + room.ID.String()
+
+	call(t, app, "POST", "/api/v1/rooms", body, 409)
+
+	response = call(t, app, "GET", path, nil, 200)
+	var fetched models.Room
+	decode(t, response.Data, &fetched)
+	if fetched.ID != room.ID || fetched.RoomNumber != "A101" {
+		t.Fatalf("fetched room %s %s, want %s A101", fetched.ID, fetched.RoomNumber, room.ID)
+	}
+
+	response = call(t, app, "PUT", path, fiber.Map{"capacity": 80, "has_projector": true}, 200)
+	var updated models.Room
+	decode(t, response.Data, &updated)
+	if updated.ID != room.ID || updated.Capacity != 80 || !updated.HasProjector {
+		t.Fatalf("updated room is %s seating %d, projector %v", updated.ID, updated.Capacity, updated.HasProjector)
+	}
+
+	call(t, app, "DELETE", path, nil, 200)
+	call(t, app, "GET", path, nil, 404)
+	call(t, app, "DELETE", path, nil, 404)
+}
+
+func TestGetRoomsFilters(t *testing.T) {
+	app := newTestApp(t)
+	seedRoom(t, "A101", "CLASSROOM", 60)
+	seedRoom(t, "B201", "LAB", 30)
+	seedRoom(t, "C301", "SEMINAR_HALL", 120)
+
+	for _, test := range []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"A101", "B201", "C301"}},
+		{"?is_lab=true", []string{"B201"}},
+		{"?is_lab=false", []string{"A101", "C301"}},
+		{"?room_type=SEMINAR_HALL", []string{"C301"}},
+		{"?min_capacity=50", []string{"A101", "C301"}},
+	} {
+		response := call(t, app, "GET", "/api/v1/rooms"+test.query, nil, 200)
+		var rooms []models.Room
+		decode(t, response.Data, &rooms)
+
+		numbers := make([]string, 0, len(rooms))
+		for _, room := range rooms {
+			numbers = append(numbers, room.RoomNumber)
+		}
+		if !slices.Equal(numbers, test.want) || response.Count != len(test.want) {
+			t.Errorf("GET /rooms%s returned %v (count %d), want %v", test.query, numbers, response.Count, test.want)
+		}
+	}
+}
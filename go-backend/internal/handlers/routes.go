@@ -3,41 +3,155 @@ package handlers
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/yourusername/timetable-scheduler/internal/config"
+	"github.com/yourusername/timetable-scheduler/internal/middleware"
 )
 
 // SetupRoutes initializes all API routes
 func SetupRoutes(api fiber.Router, cfg *config.Config) {
+	configureOptimization(cfg)
+
+	// The api group authenticates every request (see cmd/server/main.go).
+	// Most reads are open to any role; role checks guard the writes. Admins
+	// pass all of them.
+	adminOnly := middleware.RequireRoles()
+	scheduling := middleware.RequireRoles(middleware.RoleScheduler)
+	departmentStaff := middleware.RequireRoles(middleware.RoleDepartmentHead)
+	staff := middleware.RequireRoles(middleware.RoleScheduler, middleware.RoleDepartmentHead, middleware.RoleFaculty)
+	facultyOrHead := middleware.RequireRoles(middleware.RoleDepartmentHead, middleware.RoleFaculty)
+	timetableEditors := middleware.RequireRoles(middleware.RoleScheduler, middleware.RoleDepartmentHead)
+
+	// Academic structure routes
+	academic := api.Group("/academic")
+	{
+		academic.Get("/years", GetAcademicYears)
+		academic.Post("/years", adminOnly, CreateAcademicYear)
+		academic.Get("/years/:id", GetAcademicYear)
+		academic.Put("/years/:id", adminOnly, UpdateAcademicYear)
+		academic.Delete("/years/:id", adminOnly, DeleteAcademicYear)
+
+		academic.Get("/semesters", GetSemesters)
+		academic.Post("/semesters", adminOnly, CreateSemester)
+		academic.Get("/semesters/:id", GetSemester)
+		academic.Put("/semesters/:id", adminOnly, UpdateSemester)
+		academic.Delete("/semesters/:id", adminOnly, DeleteSemester)
+
+		academic.Get("/departments", GetDepartments)
+		academic.Post("/departments", adminOnly, CreateDepartment)
+		academic.Get("/departments/:id", GetDepartment)
+		academic.Put("/departments/:id", adminOnly, UpdateDepartment)
+		academic.Delete("/departments/:id", adminOnly, DeleteDepartment)
+
+		academic.Get("/programs", GetPrograms)
+		academic.Post("/programs", departmentStaff, CreateProgram)
+		academic.Get("/programs/:id", GetProgram)
+		academic.Put("/programs/:id", departmentStaff, UpdateProgram)
+		academic.Delete("/programs/:id", departmentStaff, DeleteProgram)
+	}
+
+	// Course routes
+	courses := api.Group("/courses")
+	{
+		courses.Get("/", GetCourses)
+		courses.Post("/", departmentStaff, CreateCourse)
+		courses.Get("/categories", GetCourseCategories)
+		courses.Get("/:id", GetCourse)
+		courses.Put("/:id", departmentStaff, UpdateCourse)
+		courses.Delete("/:id", departmentStaff, DeleteCourse)
+	}
+
+	// Faculty routes
+	faculty := api.Group("/faculty")
+	{
+		faculty.Get("/", GetFaculty)
+		faculty.Post("/", departmentStaff, CreateFaculty)
+		faculty.Get("/:id", GetFacultyMember)
+		faculty.Put("/:id", departmentStaff, UpdateFaculty)
+		faculty.Delete("/:id", departmentStaff, DeleteFaculty)
+
+		// Availability
+		faculty.Get("/:id/availability", GetFacultyAvailability)
+		faculty.Post("/:id/availability", facultyOrHead, AddFacultyAvailability)
+		faculty.Put("/:id/availability/:availability_id", facultyOrHead, UpdateFacultyAvailability)
+		faculty.Delete("/:id/availability/:availability_id", facultyOrHead, DeleteFacultyAvailability)
+
+		// Course expertise
+		faculty.Get("/:id/expertise", GetFacultyExpertise)
+		faculty.Post("/:id/expertise", departmentStaff, AddFacultyExpertise)
+		faculty.Delete("/:id/expertise/:expertise_id", departmentStaff, DeleteFacultyExpertise)
+	}
+
+	// Student routes
+	students := api.Group("/students")
+	{
+		students.Get("/", staff, GetStudents)
+		students.Post("/", departmentStaff, CreateStudent)
+		students.Get("/:id", staff, GetStudent)
+		students.Put("/:id", departmentStaff, UpdateStudent)
+		students.Delete("/:id", departmentStaff, DeleteStudent)
+
+		// Enrollments
+		students.Get("/:id/enrollments", staff, GetStudentEnrollments)
+		students.Post("/:id/enrollments", departmentStaff, EnrollStudent)
+		students.Delete("/:id/enrollments/:enrollment_id", departmentStaff, DeleteEnrollment)
+	}
+
 	// Room routes (classrooms and labs)
 	rooms := api.Group("/rooms")
 	{
 		rooms.Get("/", GetRooms)
-		rooms.Post("/", CreateRoom)
+		rooms.Post("/", scheduling, CreateRoom)
 		rooms.Get("/:id", GetRoom)
-		rooms.Put("/:id", UpdateRoom)
-		rooms.Delete("/:id", DeleteRoom)
+		rooms.Put("/:id", scheduling, UpdateRoom)
+		rooms.Delete("/:id", scheduling, DeleteRoom)
 	}
 
 	// Timetable routes
 	timetables := api.Group("/timetables")
 	{
+		// Routes with a timetable or class ID also check it is in the
+		// caller's department or program
 		timetables.Get("/", GetTimetables)
-		timetables.Post("/", CreateTimetable)
-		timetables.Get("/:id", GetTimetable)
-		timetables.Put("/:id", UpdateTimetable)
-		timetables.Delete("/:id", DeleteTimetable)
+		timetables.Post("/", timetableEditors, CreateTimetable)
+		timetables.Get("/:id", timetableInScope, GetTimetable)
+		timetables.Put("/:id", timetableEditors, timetableInScope, UpdateTimetable)
+		timetables.Delete("/:id", timetableEditors, timetableInScope, DeleteTimetable)
 
 		// Timetable generation
-		timetables.Post("/:id/generate", GenerateTimetable)
-		timetables.Post("/:id/publish", PublishTimetable)
+		timetables.Post("/:id/generate", scheduling, timetableInScope, GenerateTimetable)
+		timetables.Get("/:id/generate/status", timetableInScope, GetGenerationStatus)
+		timetables.Get("/:id/generate/stream", timetableInScope, StreamGenerationProgress)
+		timetables.Post("/:id/generate/cancel", scheduling, timetableInScope, CancelGeneration)
+		timetables.Post("/:id/publish", scheduling, timetableInScope, PublishTimetable)
+
+		// Generation history
+		timetables.Get("/:id/generation-runs", timetableInScope, GetGenerationRuns)
+		timetables.Get("/:id/generation-runs/:run_id", timetableInScope, GetGenerationRun)
+		timetables.Post("/:id/generation-runs/:run_id/promote", scheduling, timetableInScope, PromoteGenerationRun)
+
+		// Published versions
+		timetables.Get("/:id/versions", timetableInScope, GetTimetableVersions)
+		timetables.Get("/:id/versions/in-force", timetableInScope, GetVersionInForce)
+		timetables.Get("/:id/versions/:version", timetableInScope, GetTimetableVersion)
+		timetables.Post("/:id/versions/:version/rollback", timetableEditors, timetableInScope, RollbackTimetableVersion)
+		timetables.Get("/:id/diff", timetableInScope, GetTimetableDiff)
 
 		// Scheduled classes
-		timetables.Get("/:id/classes", GetScheduledClasses)
-		timetables.Post("/:id/classes", AddScheduledClass)
-		timetables.Put("/classes/:classId", UpdateScheduledClass)
-		timetables.Delete("/classes/:classId", DeleteScheduledClass)
+		timetables.Get("/:id/classes", timetableInScope, GetScheduledClasses)
+		timetables.Post("/:id/classes", timetableEditors, timetableInScope, AddScheduledClass)
+		timetables.Put("/classes/:classId", timetableEditors, classInScope, UpdateScheduledClass)
+		timetables.Delete("/classes/:classId", timetableEditors, classInScope, DeleteScheduledClass)
+
+		// Constraints
+		timetables.Get("/:id/constraints", timetableInScope, GetTimetableConstraints)
+		timetables.Post("/:id/constraints", timetableEditors, timetableInScope, CreateTimetableConstraint)
+		timetables.Put("/:id/constraints/:constraint_id", timetableEditors, timetableInScope, UpdateTimetableConstraint)
+		timetables.Delete("/:id/constraints/:constraint_id", timetableEditors, timetableInScope, DeleteTimetableConstraint)
 
 		// Conflicts
-		timetables.Get("/:id/conflicts", GetConflicts)
-		timetables.Post("/check-conflicts", CheckConflicts)
+		timetables.Get("/:id/conflicts", timetableInScope, GetConflicts)
+		timetables.Post("/check-conflicts", timetableEditors, CheckConflicts)
 	}
+
+	// Constraint types timetables can configure
+	api.Get("/constraint-types", GetConstraintTypes)
 }
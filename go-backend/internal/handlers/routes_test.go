@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/middleware"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestRoutesRequireValidToken(t *testing.T) {
+	// Authentication fails before any handler runs, so this needs no database
+	app := testApp()
+
+	expired := signToken(t, middleware.Claims{
+		Role: middleware.RoleAdmin,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+		},
+	})
+	otherSecret, err := jwt.NewWithClaims(jwt.SigningMethodHS256, middleware.Claims{
+		Role:             middleware.RoleAdmin,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: uuid.NewString()},
+	}).SignedString([]byte("another-secret"))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	for name, token := range map[string]string{
+		"missing":       "",
+		"malformed":     "not-a-jwt",
+		"expired":       expired,
+		"wrong secret":  otherSecret,
+		"non-UUID user": signToken(t, middleware.Claims{Role: middleware.RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{Subject: "admin"}}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			callAs(t, app, token, "GET", "/api/v1/timetables", nil, 401)
+		})
+	}
+
+	callAs(t, app, roleToken(t, "janitor", uuid.New()), "GET", "/api/v1/timetables", nil, 403)
+}
+
+func TestSchedulingRoutesRejectOtherRoles(t *testing.T) {
+	// The role checks run before the timetable is looked up, so this needs
+	// no database
+	app := testApp()
+	path := "/api/v1/timetables/" + uuid.NewString()
+
+	for _, role := range []string{middleware.RoleStudent, middleware.RoleFaculty} {
+		token := roleToken(t, role, uuid.New())
+		for _, route := range []struct{ method, path string }{
+			{"POST", path + "/generate"},
+			{"POST", path + "/generate/cancel"},
+			{"POST", path + "/publish"},
+			{"POST", path + "/generation-runs/" + uuid.NewString() + "/promote"},
+			{"POST", path + "/constraints"},
+			{"PUT", path + "/constraints/" + uuid.NewString()},
+			{"DELETE", path + "/constraints/" + uuid.NewString()},
+		} {
+			t.Run(role+" "+route.method+" "+route.path, func(t *testing.T) {
+				callAs(t, app, token, route.method, route.path, fiber.Map{}, 403)
+			})
+		}
+	}
+}
+
+func TestFacultyChangeOnlyTheirOwnAvailability(t *testing.T) {
+	app := newTestApp(t)
+
+	userID := uuid.New()
+	own := seedFaculty(t, "EMP001")
+	database.DB.Model(&own).Update("user_id", userID)
+	other := seedFaculty(t, "EMP002")
+	token := roleToken(t, middleware.RoleFaculty, userID)
+
+	body := fiber.Map{"day_of_week": 1, "start_time": "09:00", "end_time": "12:00"}
+	response := callAs(t, app, token, "POST", "/api/v1/faculty/"+own.ID.String()+"/availability", body, 201)
+	var slot models.FacultyAvailability
+	decode(t, response.Data, &slot)
+
+	otherSlot := models.FacultyAvailability{FacultyID: other.ID, DayOfWeek: 2, StartTime: "09:00", EndTime: "12:00"}
+	seed(t, &otherSlot)
+
+	otherPath := "/api/v1/faculty/" + other.ID.String() + "/availability"
+	callAs(t, app, token, "POST", otherPath, body, 403)
+	callAs(t, app, token, "PUT", otherPath+"/"+otherSlot.ID.String(), body, 403)
+	callAs(t, app, token, "DELETE", otherPath+"/"+otherSlot.ID.String(), nil, 403)
+
+	ownPath := "/api/v1/faculty/" + own.ID.String() + "/availability/" + slot.ID.String()
+	callAs(t, app, token, "PUT", ownPath, fiber.Map{"end_time": "11:00"}, 200)
+	callAs(t, app, token, "DELETE", ownPath, nil, 200)
+
+	// Faculty cannot change other master data either
+	callAs(t, app, token, "PUT", "/api/v1/faculty/"+own.ID.String(), fiber.Map{"max_hours_per_week": 40}, 403)
+}
+
+func TestCreatedRowsRecordTheCaller(t *testing.T) {
+	app := newTestApp(t)
+	semester := seedSemester(t)
+
+	userID := uuid.New()
+	token := roleToken(t, middleware.RoleScheduler, userID)
+
+	response := callAs(t, app, token, "POST", "/api/v1/timetables", fiber.Map{
+		"name":        "Fall 2025 - Computer Science",
+		"semester_id": semester.ID,
+		"created_by":  uuid.New(),
+	}, 201)
+	// The creator comes from the token, not the body
+	var timetable models.TimetableTemplate
+	decode(t, response.Data, &timetable)
+
+	var stored models.TimetableTemplate
+	database.DB.First(&stored, timetable.ID)
+	if stored.CreatedBy == nil || *stored.CreatedBy != userID {
+		t.Fatalf("timetable was created by %v, want %s", stored.CreatedBy, userID)
+	}
+
+	// Nor does an edit by someone else change who created it
+	call(t, app, "PUT", "/api/v1/timetables/"+timetable.ID.String(), fiber.Map{"created_by": uuid.New()}, 200)
+	database.DB.First(&stored, timetable.ID)
+	if stored.CreatedBy == nil || *stored.CreatedBy != userID {
+		t.Fatalf("timetable was created by %v after the edit, want %s", stored.CreatedBy, userID)
+	}
+
+	callAs(t, app, token, "POST", "/api/v1/timetables/"+timetable.ID.String()+"/publish", nil, 200)
+
+	var version models.TimetableVersion
+	database.DB.Where("timetable_id = ?", timetable.ID).First(&version)
+	if version.PublishedBy == nil || *version.PublishedBy != userID {
+		t.Fatalf("version was published by %v, want %s", version.PublishedBy, userID)
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/middleware"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// Row-level scoping. Department heads only see and change the rows of their
+// department, program coordinators those of their program. Rows outside the
+// caller's scope are hidden from reads as if they did not exist; writes to
+// them are refused with a 403.
+
+// scopeByDepartment limits a query on a table with a department_id column
+func scopeByDepartment(c *fiber.Ctx, query *gorm.DB) *gorm.DB {
+	if departmentID := middleware.ScopeOf(c).DepartmentID; departmentID != nil {
+		query = query.Where("department_id = ?", *departmentID)
+	}
+	return query
+}
+
+// scopeByProgram limits a query on a table with a program_id column
+func scopeByProgram(c *fiber.Ctx, query *gorm.DB) *gorm.DB {
+	scope := middleware.ScopeOf(c)
+	if scope.ProgramID != nil {
+		query = query.Where("program_id = ?", *scope.ProgramID)
+	}
+	if scope.DepartmentID != nil {
+		query = query.Where("program_id IN (?)",
+			database.DB.Model(&models.Program{}).Select("id").Where("department_id = ?", *scope.DepartmentID))
+	}
+	return query
+}
+
+// inDepartment reports whether a row of the given department is within the
+// caller's scope
+func inDepartment(c *fiber.Ctx, departmentID *uuid.UUID) bool {
+	scope := middleware.ScopeOf(c)
+	if scope.DepartmentID == nil {
+		return true
+	}
+	return departmentID != nil && *departmentID == *scope.DepartmentID
+}
+
+// inProgram reports whether a row of the given program is within the
+// caller's scope
+func inProgram(c *fiber.Ctx, programID *uuid.UUID) bool {
+	scope := middleware.ScopeOf(c)
+	if scope.ProgramID != nil {
+		return programID != nil && *programID == *scope.ProgramID
+	}
+	if scope.DepartmentID != nil {
+		if programID == nil {
+			return false
+		}
+		var program models.Program
+		if err := database.DB.First(&program, *programID).Error; err != nil {
+			return false
+		}
+		return inDepartment(c, program.DepartmentID)
+	}
+	return true
+}
+
+// outOfScope is the response to a write outside the caller's scope
+func outOfScope(c *fiber.Ctx) error {
+	return c.Status(403).JSON(fiber.Map{
+		"error": "You can only change records of your own department or program",
+	})
+}
+
+// timetableInScope guards routes under /timetables/:id. Timetables outside
+// the caller's scope read as not found and cannot be changed.
+func timetableInScope(c *fiber.Ctx) error {
+	timetableID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		// Let the handler report the malformed ID
+		return c.Next()
+	}
+
+	var timetable models.TimetableTemplate
+	if err := database.DB.First(&timetable, timetableID).Error; err != nil {
+		return c.Next()
+	}
+
+	return checkTimetableScope(c, timetable)
+}
+
+// classInScope guards routes under /timetables/classes/:classId by the scope
+// of the class's timetable
+func classInScope(c *fiber.Ctx) error {
+	classID, err := uuid.Parse(c.Params("classId"))
+	if err != nil {
+		return c.Next()
+	}
+
+	var class models.ScheduledClass
+	if err := database.DB.First(&class, classID).Error; err != nil {
+		return c.Next()
+	}
+
+	var timetable models.TimetableTemplate
+	if err := database.DB.First(&timetable, class.TimetableID).Error; err != nil {
+		return c.Next()
+	}
+
+	return checkTimetableScope(c, timetable)
+}
+
+func checkTimetableScope(c *fiber.Ctx, timetable models.TimetableTemplate) error {
+	if inProgram(c, timetable.ProgramID) {
+		return c.Next()
+	}
+	if c.Method() == fiber.MethodGet {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Timetable not found",
+		})
+	}
+	return outOfScope(c)
+}
+
+// scopeDepartments limits a query on departments to the caller's own
+func scopeDepartments(c *fiber.Ctx, query *gorm.DB) *gorm.DB {
+	if departmentID := middleware.ScopeOf(c).DepartmentID; departmentID != nil {
+		query = query.Where("id = ?", *departmentID)
+	}
+	return query
+}
+
+// scopePrograms limits a query on programs to those of the caller's
+// department, or to the caller's own program
+func scopePrograms(c *fiber.Ctx, query *gorm.DB) *gorm.DB {
+	if programID := middleware.ScopeOf(c).ProgramID; programID != nil {
+		query = query.Where("id = ?", *programID)
+	}
+	return scopeByDepartment(c, query)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/middleware"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+	"github.com/yourusername/timetable-scheduler/internal/optimization"
+)
+
+// scopedToken signs a token for a department head or program coordinator
+// of the given department or program
+func scopedToken(t *testing.T, role string, departmentID, programID *uuid.UUID) string {
+	t.Helper()
+
+	var claims middleware.Claims
+	claims.AppMetadata.Role = role
+	if departmentID != nil {
+		claims.AppMetadata.DepartmentID = departmentID.String()
+	}
+	if programID != nil {
+		claims.AppMetadata.ProgramID = programID.String()
+	}
+	claims.Subject = uuid.NewString()
+	return signToken(t, claims)
+}
+
+// scopeFixture is a department with a program and its own rows, twice over
+type scopeFixture struct {
+	departments [2]models.Department
+	programs    [2]models.Program
+	courses     [2]models.Course
+	faculty     [2]models.Faculty
+	students    [2]models.Student
+	timetables  [2]models.TimetableTemplate
+	classes     [2]models.ScheduledClass
+}
+
+func seedScopes(t *testing.T, app *fiber.App) scopeFixture {
+	t.Helper()
+
+	var fixture scopeFixture
+	semester := seedSemester(t)
+	for i, code := range []string{"CS", "MATH"} {
+		department := seedDepartment(t, code)
+		program := models.Program{
+			Name:          "B.Sc. " + code,
+			Code:          "BSC-" + code,
+			ProgramType:   "FYUP",
+			DepartmentID:  &department.ID,
+			DurationYears: 4,
+			TotalCredits:  160,
+		}
+		seed(t, &program)
+
+		course := models.Course{
+			Code:         code + "101",
+			Name:         "Course " + code + "101",
+			DepartmentID: &department.ID,
+			CourseType:   "THEORY",
+			Credits:      3,
+			HoursPerWeek: 3,
+		}
+		seed(t, &course)
+
+		faculty := seedFaculty(t, "EMP-"+code)
+		database.DB.Model(&faculty).Update("department_id", department.ID)
+
+		student := models.Student{
+			StudentID:     "S-" + code,
+			FirstName:     "Student",
+			LastName:      code,
+			Email:         "s-" + code + "@example.edu",
+			ProgramID:     &program.ID,
+			AdmissionYear: 2025,
+		}
+		seed(t, &student)
+
+		response := call(t, app, "POST", "/api/v1/timetables", fiber.Map{
+			"name":        "Fall 2025 - " + code,
+			"semester_id": semester.ID,
+			"program_id":  program.ID,
+		}, 201)
+		var timetable models.TimetableTemplate
+		decode(t, response.Data, &timetable)
+
+		class := models.ScheduledClass{
+			TimetableID: timetable.ID,
+			CourseID:    course.ID,
+			TimeSlotID:  timeSlot(t, timetable.ID, 1, "09:00").ID,
+			DayOfWeek:   1,
+			StartTime:   "09:00",
+			EndTime:     "10:00",
+			SemesterID:  semester.ID,
+		}
+		seed(t, &class)
+
+		fixture.departments[i] = department
+		fixture.programs[i] = program
+		fixture.courses[i] = course
+		fixture.faculty[i] = faculty
+		fixture.students[i] = student
+		fixture.timetables[i] = timetable
+		fixture.classes[i] = class
+	}
+	return fixture
+}
+
+// expectListed checks a field of each row a list endpoint responds with
+func expectListed[T any](t *testing.T, app *fiber.App, token, path string, field func(T) string, want ...string) {
+	t.Helper()
+
+	response := callAs(t, app, token, "GET", path, nil, 200)
+	var rows []T
+	decode(t, response.Data, &rows)
+
+	got := make([]string, 0, len(rows))
+	for _, row := range rows {
+		got = append(got, field(row))
+	}
+	if !slices.Equal(got, want) || response.Count != len(want) {
+		t.Errorf("GET %s returned %v (count %d), want %v", path, got, response.Count, want)
+	}
+}
+
+func TestDepartmentHeadScope(t *testing.T) {
+	app := newTestApp(t)
+	fixture := seedScopes(t, app)
+	token := scopedToken(t, middleware.RoleDepartmentHead, &fixture.departments[0].ID, nil)
+
+	expectListed(t, app, token, "/api/v1/academic/departments", func(d models.Department) string { return d.Code }, "CS")
+	expectListed(t, app, token, "/api/v1/academic/programs", func(p models.Program) string { return p.Code }, "BSC-CS")
+	expectListed(t, app, token, "/api/v1/courses", func(c models.Course) string { return c.Code }, "CS101")
+	expectListed(t, app, token, "/api/v1/faculty", func(f models.Faculty) string { return f.EmployeeID }, "EMP-CS")
+	expectListed(t, app, token, "/api/v1/students", func(s models.Student) string { return s.StudentID }, "S-CS")
+	expectListed(t, app, token, "/api/v1/timetables", func(tt models.TimetableTemplate) string { return tt.Name }, "Fall 2025 - CS")
+
+	// The other department's rows read as not found
+	other := fixture.timetables[1].ID.String()
+	for _, path := range []string{
+		"/academic/departments/" + fixture.departments[1].ID.String(),
+		"/academic/programs/" + fixture.programs[1].ID.String(),
+		"/courses/" + fixture.courses[1].ID.String(),
+		"/faculty/" + fixture.faculty[1].ID.String(),
+		"/students/" + fixture.students[1].ID.String(),
+		"/timetables/" + other,
+		"/timetables/" + other + "/classes",
+		"/timetables/" + other + "/constraints",
+		"/timetables/" + other + "/versions",
+	} {
+		callAs(t, app, token, "GET", "/api/v1"+path, nil, 404)
+	}
+	callAs(t, app, token, "GET", "/api/v1/timetables/"+fixture.timetables[0].ID.String()+"/classes", nil, 200)
+
+	// and cannot be changed
+	otherClass := "/api/v1/timetables/classes/" + fixture.classes[1].ID.String()
+	for _, write := range []struct {
+		method, path string
+		body         interface{}
+	}{
+		{"PUT", "/api/v1/academic/programs/" + fixture.programs[1].ID.String(), fiber.Map{"total_credits": 176}},
+		{"POST", "/api/v1/courses", fiber.Map{
+			"code":           "MATH102",
+			"name":           "Course MATH102",
+			"department_id":  fixture.departments[1].ID,
+			"course_type":    "THEORY",
+			"credits":        3,
+			"hours_per_week": 3,
+		}},
+		{"PUT", "/api/v1/courses/" + fixture.courses[1].ID.String(), fiber.Map{"credits": 4}},
+		{"PUT", "/api/v1/faculty/" + fixture.faculty[1].ID.String(), fiber.Map{"designation": "Lecturer"}},
+		{"PUT", "/api/v1/students/" + fixture.students[1].ID.String(), fiber.Map{"phone": "555-0100"}},
+		{"POST", "/api/v1/timetables", fiber.Map{
+			"name":        "Spring 2026 - MATH",
+			"semester_id": fixture.timetables[1].SemesterID,
+			"program_id":  fixture.programs[1].ID,
+		}},
+		{"PUT", "/api/v1/timetables/" + other, fiber.Map{"name": "Renamed"}},
+		{"DELETE", "/api/v1/timetables/" + other, nil},
+		{"POST", "/api/v1/timetables/" + other + "/classes", fiber.Map{"course_id": fixture.courses[1].ID}},
+		{"POST", "/api/v1/timetables/" + other + "/constraints", fiber.Map{"constraint_type": optimization.ConstraintFacultyWorkloadLimit}},
+		{"PUT", otherClass, fiber.Map{"is_lab": true}},
+		{"DELETE", otherClass, nil},
+	} {
+		callAs(t, app, token, write.method, write.path, write.body, 403)
+	}
+
+	var count int64
+	database.DB.Model(&models.ScheduledClass{}).Where("id = ?", fixture.classes[1].ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("the MATH class was deleted by the CS head")
+	}
+
+	// Their own timetable remains theirs to change
+	callAs(t, app, token, "PUT", "/api/v1/timetables/"+fixture.timetables[0].ID.String(), fiber.Map{"name": "Renamed"}, 200)
+	callAs(t, app, token, "DELETE", "/api/v1/timetables/classes/"+fixture.classes[0].ID.String(), nil, 200)
+}
+
+func TestProgramCoordinatorScope(t *testing.T) {
+	app := newTestApp(t)
+	fixture := seedScopes(t, app)
+	token := scopedToken(t, middleware.RoleProgramCoordinator, nil, &fixture.programs[1].ID)
+
+	expectListed(t, app, token, "/api/v1/academic/programs", func(p models.Program) string { return p.Code }, "BSC-MATH")
+	expectListed(t, app, token, "/api/v1/timetables", func(tt models.TimetableTemplate) string { return tt.Name }, "Fall 2025 - MATH")
+
+	other := fixture.timetables[0].ID.String()
+	for _, path := range []string{
+		"/academic/programs/" + fixture.programs[0].ID.String(),
+		"/timetables/" + other,
+		"/timetables/" + other + "/classes",
+		"/timetables/" + other + "/conflicts",
+	} {
+		callAs(t, app, token, "GET", "/api/v1"+path, nil, 404)
+	}
+	callAs(t, app, token, "GET", "/api/v1/timetables/"+fixture.timetables[1].ID.String()+"/classes", nil, 200)
+}
@@ -5,22 +5,23 @@ import (
 	"github.com/google/uuid"
 	"github.com/yourusername/timetable-scheduler/internal/database"
 	"github.com/yourusername/timetable-scheduler/internal/models"
+	"gorm.io/gorm/clause"
 )
 
 // GetStudents retrieves all students
 func GetStudents(c *fiber.Ctx) error {
 	var students []models.Student
 
-	query := database.DB.Preload("Program").Preload("Program.Department").Order("last_name ASC, first_name ASC")
+	query := scopeByProgram(c, database.DB).Preload("Program").Preload("Program.Department").Order("last_name ASC, first_name ASC")
 
 	// Filter by program if provided
 	if programID := c.Query("program_id"); programID != "" {
 		query = query.Where("program_id = ?", programID)
 	}
 
-	// Filter by semester if provided
+	// Filter by current semester if provided
 	if semester := c.Query("semester"); semester != "" {
-		query = query.Where("semester = ?", semester)
+		query = query.Where("current_semester = ?", semester)
 	}
 
 	result := query.Find(&students)
@@ -48,7 +49,7 @@ func GetStudent(c *fiber.Ctx) error {
 	}
 
 	var student models.Student
-	result := database.DB.
+	result := scopeByProgram(c, database.DB).
 		Preload("Program").
 		Preload("Program.Department").
 		Preload("Enrollments").
@@ -79,7 +80,7 @@ func CreateStudent(c *fiber.Ctx) error {
 	// Validation
 	if student.StudentID == "" || student.FirstName == "" || student.LastName == "" {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "Roll number, first name, and last name are required",
+			"error": "Student ID, first name, and last name are required",
 		})
 	}
 
@@ -89,11 +90,15 @@ func CreateStudent(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check for duplicate roll number
+	if !inProgram(c, student.ProgramID) {
+		return outOfScope(c)
+	}
+
+	// Check for duplicate student ID
 	var existing models.Student
-	if err := database.DB.Where("roll_number = ?", student.StudentID).First(&existing).Error; err == nil {
+	if err := database.DB.Where("student_id = ?", student.StudentID).First(&existing).Error; err == nil {
 		return c.Status(409).JSON(fiber.Map{
-			"error": "Student with this roll number already exists",
+			"error": "Student with this student ID already exists",
 		})
 	}
 
@@ -138,22 +143,35 @@ func UpdateStudent(c *fiber.Ctx) error {
 		})
 	}
 
+	if !inProgram(c, student.ProgramID) {
+		return outOfScope(c)
+	}
+
 	// Store originals to check for duplicates
 	originalStudentID := student.StudentID
 	originalEmail := student.Email
 
+	// The body may not retarget the update at another row
+	base := student.Base
 	if err := c.BodyParser(&student); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
-	// Check for duplicate roll number if changed
+	student.Base = base
+
+	// The student may not be moved out of the caller's scope either
+	if !inProgram(c, student.ProgramID) {
+		return outOfScope(c)
+	}
+
+	// Check for duplicate student ID if changed
 	if student.StudentID != originalStudentID {
 		var existing models.Student
-		if err := database.DB.Where("roll_number = ? AND id != ?", student.StudentID, studentID).First(&existing).Error; err == nil {
+		if err := database.DB.Where("student_id = ? AND id != ?", student.StudentID, studentID).First(&existing).Error; err == nil {
 			return c.Status(409).JSON(fiber.Map{
-				"error": "Student with this roll number already exists",
+				"error": "Student with this student ID already exists",
 			})
 		}
 	}
@@ -168,7 +186,7 @@ func UpdateStudent(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := database.DB.Save(&student).Error; err != nil {
+	if err := database.DB.Omit(clause.Associations).Save(&student).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update student",
 		})
@@ -194,6 +212,17 @@ func DeleteStudent(c *fiber.Ctx) error {
 		})
 	}
 
+	var student models.Student
+	if err := database.DB.First(&student, studentID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Student not found",
+		})
+	}
+
+	if !inProgram(c, student.ProgramID) {
+		return outOfScope(c)
+	}
+
 	result := database.DB.Delete(&models.Student{}, studentID)
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -223,6 +252,14 @@ func GetStudentEnrollments(c *fiber.Ctx) error {
 		})
 	}
 
+	// Students outside the caller's scope read as not found
+	var student models.Student
+	if err := scopeByProgram(c, database.DB).First(&student, studentID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Student not found",
+		})
+	}
+
 	var enrollments []models.StudentEnrollment
 	result := database.DB.
 		Preload("Course").
@@ -261,6 +298,10 @@ func EnrollStudent(c *fiber.Ctx) error {
 		})
 	}
 
+	if !inProgram(c, student.ProgramID) {
+		return outOfScope(c)
+	}
+
 	var enrollment models.StudentEnrollment
 	if err := c.BodyParser(&enrollment); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -323,6 +364,17 @@ func DeleteEnrollment(c *fiber.Ctx) error {
 		})
 	}
 
+	var student models.Student
+	if err := database.DB.Where("id = ?", studentID).First(&student).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Student not found",
+		})
+	}
+
+	if !inProgram(c, student.ProgramID) {
+		return outOfScope(c)
+	}
+
 	result := database.DB.Where("id = ? AND student_id = ?", enrID, studentID).Delete(&models.StudentEnrollment{})
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestStudentRoutes(t *testing.T) {
+	app := newTestApp(t)
+	semester := seedSemester(t)
+	course := seedCourse(t, "CS101", "THEORY")
+
+	body := fiber.Map{
+		"student_id":       "S001",
+		"first_name":       "Grace",
+		"last_name":        "Hopper",
+		"email":            "grace@example.edu",
+		"current_semester": 3,
+		"admission_year":   2024,
+	}
+	response := call(t, app, "POST", "/api/v1/students", body, 201)
+	var student models.Student
+	decode(t, response.Data, &student)
+	path := "/api/v1/students/" + student.ID.String()
+
+	// Duplicates are found by student ID
+	body["email"] = "grace.hopper@example.edu"
+	call(t, app, "POST", "/api/v1/students", body, 409)
+
+	call(t, app, "POST", path+"/enrollments", fiber.Map{
+		"course_id":   course.ID,
+		"semester_id": semester.ID,
+	}, 201)
+
+	response = call(t, app, "GET", path, nil, 200)
+	var fetched models.Student
+	decode(t, response.Data, &fetched)
+	if len(fetched.Enrollments) != 1 || fetched.Enrollments[0].Course.Code != "CS101" {
+		t.Fatalf("fetched student has enrollments %+v, want CS101", fetched.Enrollments)
+	}
+
+	response = call(t, app, "PUT", path, fiber.Map{"last_name": "Murray Hopper"}, 200)
+	var updated models.Student
+	decode(t, response.Data, &updated)
+	if updated.ID != student.ID || updated.LastName != "Murray Hopper" || updated.StudentID != "S001" {
+		t.Fatalf("updated student is %s %s %q", updated.ID, updated.StudentID, updated.LastName)
+	}
+
+	call(t, app, "DELETE", path, nil, 200)
+	call(t, app, "GET", path, nil, 404)
+	call(t, app, "DELETE", path, nil, 404)
+}
+
+func TestGetStudentsBySemester(t *testing.T) {
+	app := newTestApp(t)
+	for i, semester := range []int{1, 3, 3} {
+		student := models.Student{
+			StudentID:       fmt.Sprintf("S%03d", i+1),
+			FirstName:       "Student",
+			LastName:        fmt.Sprintf("%c", 'A'+i),
+			Email:           fmt.Sprintf("student%d@example.edu", i+1),
+			CurrentSemester: &semester,
+			AdmissionYear:   2024,
+		}
+		seed(t, &student)
+	}
+
+	response := call(t, app, "GET", "/api/v1/students?semester=3", nil, 200)
+	var students []models.Student
+	decode(t, response.Data, &students)
+
+	ids := make([]string, 0, len(students))
+	for _, student := range students {
+		ids = append(ids, student.StudentID)
+	}
+	if want := []string{"S002", "S003"}; !slices.Equal(ids, want) {
+		t.Fatalf("students in semester 3 are %v, want %v", ids, want)
+	}
+}
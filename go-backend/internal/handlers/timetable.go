@@ -1,22 +1,31 @@
 package handlers
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/config"
 	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/jobs"
+	"github.com/yourusername/timetable-scheduler/internal/middleware"
 	"github.com/yourusername/timetable-scheduler/internal/models"
 	"github.com/yourusername/timetable-scheduler/internal/optimization"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // GetTimetables retrieves all timetables
 func GetTimetables(c *fiber.Ctx) error {
 	var timetables []models.TimetableTemplate
 
-	result := database.DB.Preload("Semester").Preload("Program").Order("created_at DESC").Find(&timetables)
+	result := scopeByProgram(c, database.DB).Preload("Semester").Preload("Program").Order("created_at DESC").Find(&timetables)
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to fetch timetables",
@@ -41,7 +50,7 @@ func GetTimetable(c *fiber.Ctx) error {
 	}
 
 	var timetable models.TimetableTemplate
-	result := database.DB.
+	result := scopeByProgram(c, database.DB).
 		Preload("Semester").
 		Preload("Program").
 		Preload("ScheduledClasses").
@@ -86,10 +95,17 @@ func CreateTimetable(c *fiber.Ctx) error {
 		})
 	}
 
+	if !inProgram(c, timetable.ProgramID) {
+		return outOfScope(c)
+	}
+
 	// Set default status
 	timetable.Status = "DRAFT"
 	timetable.IsPublished = false
 
+	userID := middleware.UserID(c)
+	timetable.CreatedBy = &userID
+
 	result := database.DB.Create(&timetable)
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -124,13 +140,35 @@ func UpdateTimetable(c *fiber.Ctx) error {
 		})
 	}
 
+	createdBy := timetable.CreatedBy
+	status, isPublished, publishedAt := timetable.Status, timetable.IsPublished, timetable.PublishedAt
+
+	// The body may not retarget the update at another row
+	base := timetable.Base
 	if err := c.BodyParser(&timetable); err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
-	if err := database.DB.Save(&timetable).Error; err != nil {
+	timetable.Base = base
+
+	// The creator is set once, from the token
+	timetable.CreatedBy = createdBy
+
+	// Publishing goes through PublishTimetable; editing a published timetable
+	// starts a draft of its next version
+	timetable.Status, timetable.IsPublished, timetable.PublishedAt = status, isPublished, publishedAt
+	if timetable.Status == "PUBLISHED" {
+		timetable.Status = "DRAFT"
+	}
+
+	// The timetable may not be moved out of the caller's scope
+	if !inProgram(c, timetable.ProgramID) {
+		return outOfScope(c)
+	}
+
+	if err := database.DB.Omit(clause.Associations).Save(&timetable).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update timetable",
 		})
@@ -209,6 +247,13 @@ func AddScheduledClass(c *fiber.Ctx) error {
 		})
 	}
 
+	var timetable models.TimetableTemplate
+	if err := database.DB.First(&timetable, timetableID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Timetable not found",
+		})
+	}
+
 	var class models.ScheduledClass
 	if err := c.BodyParser(&class); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -217,9 +262,12 @@ func AddScheduledClass(c *fiber.Ctx) error {
 	}
 
 	class.TimetableID = timetableID
+	if class.SemesterID == uuid.Nil {
+		class.SemesterID = timetable.SemesterID
+	}
 
 	// Check for conflicts
-	conflicts := detectConflicts(&class)
+	conflicts := detectConflicts(&class, timetable)
 	if len(conflicts) > 0 {
 		return c.Status(409).JSON(fiber.Map{
 			"error":     "Scheduling conflicts detected",
@@ -227,8 +275,13 @@ func AddScheduledClass(c *fiber.Ctx) error {
 		})
 	}
 
-	result := database.DB.Create(&class)
-	if result.Error != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&class).Error; err != nil {
+			return err
+		}
+		return startDraftVersion(tx, timetableID)
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to create scheduled class",
 		})
@@ -280,8 +333,15 @@ func UpdateScheduledClass(c *fiber.Ctx) error {
 	class.StartTime = updates.StartTime
 	class.EndTime = updates.EndTime
 
+	var timetable models.TimetableTemplate
+	if err := database.DB.First(&timetable, class.TimetableID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Timetable not found",
+		})
+	}
+
 	// Check for conflicts
-	conflicts := detectConflicts(&class)
+	conflicts := detectConflicts(&class, timetable)
 	if len(conflicts) > 0 {
 		return c.Status(409).JSON(fiber.Map{
 			"error":     "Scheduling conflicts detected",
@@ -289,7 +349,13 @@ func UpdateScheduledClass(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := database.DB.Save(&class).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&class).Error; err != nil {
+			return err
+		}
+		return startDraftVersion(tx, class.TimetableID)
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update scheduled class",
 		})
@@ -312,8 +378,20 @@ func DeleteScheduledClass(c *fiber.Ctx) error {
 		})
 	}
 
-	result := database.DB.Delete(&models.ScheduledClass{}, id)
-	if result.Error != nil {
+	var class models.ScheduledClass
+	if err := database.DB.First(&class, id).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Scheduled class not found",
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&class).Error; err != nil {
+			return err
+		}
+		return startDraftVersion(tx, class.TimetableID)
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to delete scheduled class",
 		})
@@ -324,7 +402,45 @@ func DeleteScheduledClass(c *fiber.Ctx) error {
 	})
 }
 
-// GenerateTimetable generates timetable using AI optimization
+// generationJobs runs timetable generation in the background
+var generationJobs = jobs.NewManager()
+
+// optimizationConfig holds the engine settings for generation runs. The
+// algorithm, timeout and worker count come from the server configuration.
+var optimizationConfig = optimization.EngineConfig{
+	Algorithm:      "hybrid",
+	MaxIterations:  10000,
+	Timeout:        5 * time.Minute,
+	Workers:        8,
+	PopulationSize: 100,
+	Temperature:    1000.0,
+}
+
+// configureOptimization applies the OPTIMIZATION_* settings
+func configureOptimization(cfg *config.Config) {
+	if cfg.OptimizationAlgorithm != "" {
+		optimizationConfig.Algorithm = cfg.OptimizationAlgorithm
+	}
+	if cfg.OptimizationTimeout > 0 {
+		optimizationConfig.Timeout = time.Duration(cfg.OptimizationTimeout) * time.Second
+	}
+	if cfg.OptimizationWorkers > 0 {
+		optimizationConfig.Workers = cfg.OptimizationWorkers
+	}
+	if len(cfg.OptimizationRoomCompatibility) > 0 {
+		optimizationConfig.RoomCompatibility = cfg.OptimizationRoomCompatibility
+	}
+}
+
+// generateRequest is the optional body of GenerateTimetable
+type generateRequest struct {
+	// Seed reproduces an earlier run when given with the same data. A run
+	// without a seed, or with 0, gets one from the clock.
+	Seed *int64 `json:"seed"`
+}
+
+// GenerateTimetable starts generating a timetable using AI optimization. The
+// work runs as a background job; poll GetGenerationStatus for its progress.
 func GenerateTimetable(c *fiber.Ctx) error {
 	id := c.Params("id")
 
@@ -342,74 +458,402 @@ func GenerateTimetable(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update status
-	timetable.Status = "GENERATING"
-	timetable.GenerationStartTime = timePtr(time.Now())
-	database.DB.Save(&timetable)
+	var request generateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	userID := middleware.UserID(c)
+	job, err := generationJobs.Start(timetableID, func(ctx context.Context, job *jobs.Job) (interface{}, error) {
+		return runGeneration(ctx, job, timetable, request.Seed, userID)
+	})
+	if errors.Is(err, jobs.ErrAlreadyRunning) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Timetable is already being generated",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to start timetable generation",
+		})
+	}
+
+	return c.Status(202).JSON(fiber.Map{
+		"message": "Timetable generation started",
+		"data":    job.Snapshot(),
+	})
+}
+
+// runGeneration is the body of a generation job. If it does not finish
+// successfully the timetable goes back to the status it had before. Once the
+// search starts the run is recorded as a GenerationRun, however it ends.
+func runGeneration(ctx context.Context, job *jobs.Job, timetable models.TimetableTemplate, seed *int64, userID uuid.UUID) (interface{}, error) {
+	timetableID := timetable.ID
+
+	previousStatus := timetable.Status
+	if previousStatus == "GENERATING" {
+		previousStatus = "DRAFT"
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			database.DB.Model(&models.TimetableTemplate{}).
+				Where("id = ?", timetableID).
+				Update("status", previousStatus)
+		}
+	}()
+
+	// Update status. Only the generation columns are written, here and when
+	// the run ends, so edits made to the timetable meanwhile are kept.
+	database.DB.Model(&models.TimetableTemplate{}).
+		Where("id = ?", timetableID).
+		Updates(map[string]interface{}{
+			"status":                "GENERATING",
+			"generation_start_time": time.Now(),
+		})
+
+	// Create optimization engine
+	engineConfig := optimizationConfig
+	if seed != nil {
+		engineConfig.Seed = *seed
+	}
+	engine, courses, constraints, err := newGenerationEngine(timetable, &engineConfig)
+	if err != nil {
+		return nil, err
+	}
+	usedSeed := engine.Seed()
+	recorder := &runRecorder{}
+	engine.OnProgress(func(progress optimization.Progress) {
+		recorder.record(progress)
+		job.SetProgress(progress)
+	})
+
+	run, err := startGenerationRun(job, timetableID, engineConfig, usedSeed, constraints, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record generation run: %w", err)
+	}
+
+	// Generate timetable
+	solution, err := engine.Generate(ctx)
+	if err != nil {
+		finishGenerationRun(database.DB, run, jobs.StatusFailed, err, recorder, engine, nil)
+		return nil, fmt.Errorf("failed to generate timetable: %w", err)
+	}
+
+	// A cancelled run keeps the previous schedule
+	if ctx.Err() != nil {
+		finishGenerationRun(database.DB, run, jobs.StatusCancelled, nil, recorder, engine, solution)
+		return nil, ctx.Err()
+	}
+
+	// Replace the schedule, record what the solution still breaks and mark
+	// the timetable generated all at once, so a failure keeps the old schedule
+	violations := engine.Violations(solution)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveSolutionToDatabase(tx, timetable, courses, solution); err != nil {
+			return fmt.Errorf("failed to save generated timetable: %w", err)
+		}
+
+		if err := saveConflictLogs(tx, timetableID, violations); err != nil {
+			return fmt.Errorf("failed to save conflict logs: %w", err)
+		}
+
+		// Update timetable status
+		err := tx.Model(&models.TimetableTemplate{}).
+			Where("id = ?", timetableID).
+			Updates(map[string]interface{}{
+				"status":              generatedStatus(previousStatus),
+				"generation_end_time": time.Now(),
+				"algorithm_used":      engineConfig.Algorithm,
+				"generation_seed":     usedSeed,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update timetable status: %w", err)
+		}
+
+		if err := finishGenerationRun(tx, run, jobs.StatusCompleted, nil, recorder, engine, solution); err != nil {
+			return fmt.Errorf("failed to record generation run: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		finishGenerationRun(database.DB, run, jobs.StatusFailed, err, recorder, engine, solution)
+		return nil, err
+	}
+	succeeded = true
+
+	return fiber.Map{
+		"hard_violations":   solution.HardViolations,
+		"soft_violations":   solution.SoftViolations,
+		"fitness_score":     solution.FitnessScore,
+		"classes_scheduled": len(solution.Schedule),
+		"issues":            solution.Issues,
+		"violations":        violations,
+		"seed":              usedSeed,
+		"run_id":            run.ID,
+	}, nil
+}
+
+// newGenerationEngine creates an engine loaded with the timetable's courses,
+// faculty, rooms, time slots, enrollments and constraints
+func newGenerationEngine(timetable models.TimetableTemplate, config *optimization.EngineConfig) (*optimization.TimetableEngine, []models.Course, []models.TimetableConstraint, error) {
+	timetableID := timetable.ID
 
 	// Get all required data
 	var courses []models.Course
 	var faculty []models.Faculty
 	var rooms []models.Room
 	var timeSlots []models.TimeSlot
+	var enrollments []models.StudentEnrollment
 
-	database.DB.Where("is_active = ?", true).Find(&courses)
-	database.DB.Where("is_active = ?", true).Preload("Availability").Preload("CourseExpertise").Find(&faculty)
-	database.DB.Where("is_available = ?", true).Find(&rooms)
-	database.DB.Where("timetable_id = ?", timetableID).Find(&timeSlots)
+	// Rows are read in a fixed order so a seed reproduces the same run
+	database.DB.Where("is_active = ?", true).Preload("Category").Order("id").Find(&courses)
+	database.DB.Where("is_active = ?", true).Preload("Availability").Preload("CourseExpertise").Order("id").Find(&faculty)
+	database.DB.Where("is_available = ?", true).Order("id").Find(&rooms)
+	database.DB.Where("timetable_id = ?", timetableID).Order("day_of_week, start_time, id").Find(&timeSlots)
+	database.DB.Where("semester_id = ?", timetable.SemesterID).Order("id").Find(&enrollments)
 
-	// Create optimization engine
-	engine := optimization.NewTimetableEngine(timetableID, &optimization.EngineConfig{
-		Algorithm:      "hybrid",
-		MaxIterations:  10000,
-		Timeout:        5 * time.Minute,
-		Workers:        8,
-		PopulationSize: 100,
-		Temperature:    1000.0,
-	})
+	engine := optimization.NewTimetableEngine(timetableID, config)
 
 	// Load data
 	engine.LoadData(courses, faculty, rooms, timeSlots)
+	engine.LoadEnrollments(enrollments)
+
+	// Courses sharing students, from enrollments and the program's curriculum
+	courseConflicts := optimization.BuildCourseConflictGraph(enrollments, getCurriculumCohorts(timetable, courses))
+	engine.SetCourseConflicts(courseConflicts)
+
+	// Add the constraints configured for this timetable
+	var constraints []models.TimetableConstraint
+	database.DB.Where("timetable_id = ?", timetableID).Order("created_at").Find(&constraints)
+	err := engine.LoadConstraints(&optimization.ConstraintInput{
+		Courses:     courses,
+		Faculty:     faculty,
+		Rooms:       rooms,
+		Enrollments: enrollments,
+		Conflicts:   courseConflicts,
+	}, constraints)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load timetable constraints: %w", err)
+	}
 
-	// Add constraints
-	addOptimizationConstraints(engine, faculty, rooms, courses)
+	return engine, courses, constraints, nil
+}
 
-	// Generate timetable
-	ctx := context.Background()
-	solution, err := engine.Generate(ctx)
+// findGenerationJob returns the job named by the job_id query parameter, or
+// the timetable's latest job when none is given
+func findGenerationJob(c *fiber.Ctx, timetableID uuid.UUID) (*jobs.Job, bool) {
+	if jobID := c.Query("job_id"); jobID != "" {
+		id, err := uuid.Parse(jobID)
+		if err != nil {
+			return nil, false
+		}
+		job, ok := generationJobs.Get(id)
+		if !ok || job.TimetableID != timetableID {
+			return nil, false
+		}
+		return job, true
+	}
+
+	return generationJobs.Latest(timetableID)
+}
+
+// GetGenerationStatus reports the phase, iteration, best fitness and
+// violations of a timetable's generation job
+func GetGenerationStatus(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	timetableID, err := uuid.Parse(id)
 	if err != nil {
-		timetable.Status = "DRAFT"
-		database.DB.Save(&timetable)
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to generate timetable: " + err.Error(),
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	job, ok := findGenerationJob(c, timetableID)
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Generation job not found",
 		})
 	}
 
-	// Save solution to database
-	err = saveSolutionToDatabase(timetableID, solution)
+	return c.JSON(fiber.Map{
+		"data": job.Snapshot(),
+	})
+}
+
+// progressInterval is the shortest gap between two streamed progress events
+// of the same phase
+const progressInterval = 250 * time.Millisecond
+
+// StreamGenerationProgress streams a generation job's progress as Server-Sent
+// Events. A "progress" event is sent on every phase change and otherwise at
+// most once per progressInterval, followed by a single "done" event carrying
+// the final job status.
+func StreamGenerationProgress(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	timetableID, err := uuid.Parse(id)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to save generated timetable",
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	job, ok := findGenerationJob(c, timetableID)
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Generation job not found",
 		})
 	}
 
-	// Update timetable status
-	timetable.Status = "GENERATED"
-	timetable.GenerationEndTime = timePtr(time.Now())
-	timetable.AlgorithmUsed = strPtr("hybrid")
-	database.DB.Save(&timetable)
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	updates, unsubscribe := job.Subscribe()
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		last := job.Snapshot().Progress
+		if writeEvent(w, "progress", last) != nil {
+			return
+		}
+		lastSent := time.Now()
+
+		var pending *optimization.Progress
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case progress := <-updates:
+				if progress.Phase == last.Phase && time.Since(lastSent) < progressInterval {
+					pending = &progress
+					continue
+				}
+				if writeEvent(w, "progress", progress) != nil {
+					return
+				}
+				last, lastSent, pending = progress, time.Now(), nil
+
+			case <-ticker.C:
+				if pending != nil {
+					if writeEvent(w, "progress", *pending) != nil {
+						return
+					}
+					last, lastSent, pending = *pending, time.Now(), nil
+				} else if time.Since(lastSent) >= 15*time.Second {
+					// Comment line so proxies keep the connection open and
+					// a closed client is noticed
+					if _, err := w.WriteString(": keep-alive\n\n"); err != nil || w.Flush() != nil {
+						return
+					}
+					lastSent = time.Now()
+				}
+
+			case <-job.Done():
+				writeEvent(w, "done", job.Snapshot())
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// writeEvent writes one Server-Sent Event and flushes it to the client. An
+// error means the client has gone away.
+func writeEvent(w *bufio.Writer, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// CancelGeneration stops a running generation job. The timetable keeps its
+// previous schedule and status.
+func CancelGeneration(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	timetableID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	job, ok := findGenerationJob(c, timetableID)
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Generation job not found",
+		})
+	}
+
+	if !generationJobs.Cancel(job) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Generation job is not running",
+		})
+	}
+
+	// The search checks for cancellation every iteration, so this is brief
+	select {
+	case <-job.Done():
+	case <-time.After(5 * time.Second):
+	}
 
 	return c.JSON(fiber.Map{
-		"message": "Timetable generated successfully",
-		"data": fiber.Map{
-			"hard_violations": solution.HardViolations,
-			"soft_violations": solution.SoftViolations,
-			"fitness_score":   solution.FitnessScore,
-			"classes_scheduled": len(solution.Schedule),
-		},
+		"message": "Timetable generation cancelled",
+		"data":    job.Snapshot(),
 	})
 }
 
+// RecoverInterruptedGenerations resets timetables left GENERATING by a server
+// that stopped mid-run. Those with a saved schedule go back to GENERATED and
+// the rest to DRAFT, and their runs are marked failed. Call it once at
+// startup, before serving requests.
+func RecoverInterruptedGenerations() error {
+	err := database.DB.Model(&models.GenerationRun{}).
+		Where("status = ?", jobs.StatusRunning).
+		Updates(map[string]interface{}{
+			"status": jobs.StatusFailed,
+			"error":  "Interrupted by a server restart",
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	scheduled := database.DB.Model(&models.ScheduledClass{}).Select("timetable_id")
+
+	err = database.DB.Model(&models.TimetableTemplate{}).
+		Where("status = ? AND id IN (?)", "GENERATING", scheduled).
+		Update("status", "GENERATED").Error
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Model(&models.TimetableTemplate{}).
+		Where("status = ?", "GENERATING").
+		Update("status", "DRAFT").Error
+}
+
+// ShutdownGenerations cancels running generation jobs and waits for them to
+// restore their timetables' status
+func ShutdownGenerations(ctx context.Context) {
+	generationJobs.Shutdown(ctx)
+}
+
 // GetConflicts retrieves all conflicts for a timetable
 func GetConflicts(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -439,7 +883,8 @@ func GetConflicts(c *fiber.Ctx) error {
 	})
 }
 
-// CheckConflicts checks for conflicts in a proposed schedule
+// CheckConflicts checks a proposed class for conflicts within the timetable
+// given by its timetable_id
 func CheckConflicts(c *fiber.Ctx) error {
 	var class models.ScheduledClass
 	if err := c.BodyParser(&class); err != nil {
@@ -448,7 +893,20 @@ func CheckConflicts(c *fiber.Ctx) error {
 		})
 	}
 
-	conflicts := detectConflicts(&class)
+	if class.TimetableID == uuid.Nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Timetable ID is required",
+		})
+	}
+
+	var timetable models.TimetableTemplate
+	if err := scopeByProgram(c, database.DB).First(&timetable, class.TimetableID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Timetable not found",
+		})
+	}
+
+	conflicts := detectConflicts(&class, timetable)
 
 	return c.JSON(fiber.Map{
 		"has_conflicts": len(conflicts) > 0,
@@ -475,6 +933,19 @@ func PublishTimetable(c *fiber.Ctx) error {
 		})
 	}
 
+	// A published version cannot change; edits start the next one
+	if timetable.Status == "PUBLISHED" {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Timetable is already published; edit it to start a new version",
+		})
+	}
+
+	if timetable.Status == "GENERATING" {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Timetable is being generated",
+		})
+	}
+
 	// Check if there are any unresolved conflicts
 	var conflictCount int64
 	database.DB.Model(&models.ConflictLog{}).
@@ -487,19 +958,29 @@ func PublishTimetable(c *fiber.Ctx) error {
 		})
 	}
 
-	timetable.Status = "PUBLISHED"
-	timetable.IsPublished = true
-	timetable.PublishedAt = timePtr(time.Now())
+	now := time.Now()
+	var version *models.TimetableVersion
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if version, err = publishVersion(tx, timetable, now, middleware.UserID(c)); err != nil {
+			return err
+		}
 
-	if err := database.DB.Save(&timetable).Error; err != nil {
+		timetable.Status = "PUBLISHED"
+		timetable.IsPublished = true
+		timetable.PublishedAt = &now
+		return tx.Save(&timetable).Error
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to publish timetable",
 		})
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Timetable published successfully",
+		"message": fmt.Sprintf("Timetable published as version %d", version.Version),
 		"data":    timetable,
+		"version": version.Version,
 	})
 }
 
@@ -585,92 +1066,342 @@ type Conflict struct {
 	Severity    string `json:"severity"`
 }
 
-func detectConflicts(class *models.ScheduledClass) []Conflict {
+// detectConflicts checks a class against the rest of its timetable and the
+// versions in force of the semester's other timetables, and against its
+// course, room and faculty member. Conflict types and severities are the
+// ones the engine reports.
+func detectConflicts(class *models.ScheduledClass, timetable models.TimetableTemplate) []Conflict {
 	conflicts := []Conflict{}
 
+	competing := competingClasses(class, timetable)
+
 	// Check faculty double-booking
-	if class.FacultyID != nil {
-		var count int64
-		database.DB.Model(&models.ScheduledClass{}).
-			Where("faculty_id = ? AND day_of_week = ? AND id != ?", class.FacultyID, class.DayOfWeek, class.ID).
-			Where("(start_time < ? AND end_time > ?) OR (start_time < ? AND end_time > ?)",
-				class.EndTime, class.StartTime, class.StartTime, class.StartTime).
-			Count(&count)
-
-		if count > 0 {
-			conflicts = append(conflicts, Conflict{
-				Type:        "FACULTY_DOUBLE_BOOKING",
-				Description: "Faculty is already assigned to another class at this time",
-				Severity:    "CRITICAL",
-			})
-		}
+	if class.FacultyID != nil && anyOverlapping(class, competing, func(other models.VersionClass) bool {
+		return other.FacultyID != nil && *other.FacultyID == *class.FacultyID
+	}) {
+		conflicts = append(conflicts, Conflict{
+			Type:        optimization.ConflictFacultyDoubleBooking,
+			Description: "Faculty is already assigned to another class at this time",
+			Severity:    optimization.SeverityCritical,
+		})
 	}
 
 	// Check room double-booking
+	if class.RoomID != nil && anyOverlapping(class, competing, func(other models.VersionClass) bool {
+		return other.RoomID != nil && *other.RoomID == *class.RoomID
+	}) {
+		conflicts = append(conflicts, Conflict{
+			Type:        optimization.ConflictRoomDoubleBooking,
+			Description: "Room is already booked for another class at this time",
+			Severity:    optimization.SeverityCritical,
+		})
+	}
+
+	var course models.Course
+	courseFound := database.DB.First(&course, class.CourseID).Error == nil
+
 	if class.RoomID != nil {
-		var count int64
-		database.DB.Model(&models.ScheduledClass{}).
-			Where("room_id = ? AND day_of_week = ? AND id != ?", class.RoomID, class.DayOfWeek, class.ID).
-			Where("(start_time < ? AND end_time > ?) OR (start_time < ? AND end_time > ?)",
-				class.EndTime, class.StartTime, class.StartTime, class.StartTime).
-			Count(&count)
-
-		if count > 0 {
-			conflicts = append(conflicts, Conflict{
-				Type:        "ROOM_DOUBLE_BOOKING",
-				Description: "Room is already booked for another class at this time",
-				Severity:    "CRITICAL",
-			})
+		var room models.Room
+		if err := database.DB.First(&room, *class.RoomID).Error; err == nil {
+			// Check the room seats everyone enrolled in the course
+			var enrolled int64
+			database.DB.Model(&models.StudentEnrollment{}).
+				Where("course_id = ? AND semester_id = ? AND status != ?", class.CourseID, timetable.SemesterID, "DROPPED").
+				Count(&enrolled)
+
+			if int(enrolled) > room.Capacity {
+				conflicts = append(conflicts, Conflict{
+					Type:        optimization.ConflictRoomCapacity,
+					Description: fmt.Sprintf("Room seats %d but %d students are enrolled", room.Capacity, enrolled),
+					Severity:    optimization.SeverityHigh,
+				})
+			}
+
+			// Check lab classes are in a lab room
+			if courseFound && course.CourseType == "LAB" && room.RoomType != "LAB" {
+				conflicts = append(conflicts, Conflict{
+					Type:        optimization.ConflictLabRoom,
+					Description: "Lab class is not scheduled in a lab room",
+					Severity:    optimization.SeverityHigh,
+				})
+			}
+		}
+	}
+
+	if class.FacultyID != nil {
+		var faculty models.Faculty
+		if err := database.DB.Preload("Availability").First(&faculty, *class.FacultyID).Error; err == nil {
+			// Check the faculty member is available, if they recorded availability
+			if len(faculty.Availability) > 0 && !withinAvailability(class, faculty.Availability) {
+				conflicts = append(conflicts, Conflict{
+					Type:        optimization.ConflictFacultyUnavailable,
+					Description: "Class is scheduled outside the faculty member's availability",
+					Severity:    optimization.SeverityHigh,
+				})
+			}
+
+			// Check the class keeps the faculty member within their weekly hours
+			minutes := classMinutes(class.StartTime, class.EndTime)
+			for _, other := range competing {
+				if other.FacultyID != nil && *other.FacultyID == *class.FacultyID {
+					minutes += classMinutes(other.StartTime, other.EndTime)
+				}
+			}
+
+			if minutes > faculty.MaxHoursPerWeek*60 {
+				conflicts = append(conflicts, Conflict{
+					Type: optimization.ConflictFacultyOverload,
+					Description: fmt.Sprintf("Faculty member is scheduled for %d hours, above the limit of %d",
+						(minutes+59)/60, faculty.MaxHoursPerWeek),
+					Severity: optimization.SeverityHigh,
+				})
+			}
 		}
 	}
 
 	return conflicts
 }
 
-func addOptimizationConstraints(engine *optimization.TimetableEngine, faculty []models.Faculty, rooms []models.Room, courses []models.Course) {
-	// Add hard constraints
-	engine.AddConstraint("no_faculty_double_booking", &optimization.NoFacultyDoubleBooking{})
-	engine.AddConstraint("no_room_double_booking", &optimization.NoRoomDoubleBooking{})
+// competingClasses returns the classes a class competes with for faculty and
+// rooms: the rest of its own timetable's working schedule, and the version in
+// force of every other timetable of the semester. A published timetable that
+// is being edited keeps counting with what it published until it is
+// published again.
+func competingClasses(class *models.ScheduledClass, timetable models.TimetableTemplate) []models.VersionClass {
+	var own []models.ScheduledClass
+	database.DB.
+		Select("faculty_id", "room_id", "day_of_week", "start_time", "end_time").
+		Where("timetable_id = ? AND id != ?", timetable.ID, class.ID).
+		Find(&own)
+
+	competing := make([]models.VersionClass, 0, len(own))
+	for _, other := range own {
+		competing = append(competing, models.VersionClass{
+			FacultyID: other.FacultyID,
+			RoomID:    other.RoomID,
+			DayOfWeek: other.DayOfWeek,
+			StartTime: other.StartTime,
+			EndTime:   other.EndTime,
+		})
+	}
 
-	// Add soft constraints
-	engine.AddConstraint("prefer_morning_theory", &optimization.PreferMorningForTheory{
-		TheoryCourses: getTheoryCourseIDs(courses),
-	})
+	var inForce []models.TimetableVersion
+	database.DB.
+		Where("superseded_at IS NULL AND timetable_id IN (?)",
+			database.DB.Model(&models.TimetableTemplate{}).
+				Select("id").
+				Where("semester_id = ? AND id != ?", timetable.SemesterID, timetable.ID)).
+		Find(&inForce)
+	for _, version := range inForce {
+		competing = append(competing, version.Classes...)
+	}
+
+	return competing
 }
 
-func getTheoryCourseIDs(courses []models.Course) []string {
-	ids := []string{}
+// anyOverlapping reports whether one of the classes the test accepts meets on
+// the class's day at an overlapping time
+func anyOverlapping(class *models.ScheduledClass, classes []models.VersionClass, accept func(other models.VersionClass) bool) bool {
+	start, errStart := optimization.ParseClock(class.StartTime)
+	end, errEnd := optimization.ParseClock(class.EndTime)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+
+	for _, other := range classes {
+		if other.DayOfWeek != class.DayOfWeek || !accept(other) {
+			continue
+		}
+		// Two intervals overlap when each starts before the other ends
+		from, errFrom := optimization.ParseClock(other.StartTime)
+		to, errTo := optimization.ParseClock(other.EndTime)
+		if errFrom == nil && errTo == nil && from < end && to > start {
+			return true
+		}
+	}
+	return false
+}
+
+// withinAvailability reports whether the class falls inside one of the
+// available time ranges on its day
+func withinAvailability(class *models.ScheduledClass, availability []models.FacultyAvailability) bool {
+	start, errStart := optimization.ParseClock(class.StartTime)
+	end, errEnd := optimization.ParseClock(class.EndTime)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+
+	for _, slot := range availability {
+		if !slot.IsAvailable || slot.DayOfWeek != class.DayOfWeek {
+			continue
+		}
+		from, errFrom := optimization.ParseClock(slot.StartTime)
+		to, errTo := optimization.ParseClock(slot.EndTime)
+		if errFrom == nil && errTo == nil && start >= from && end <= to {
+			return true
+		}
+	}
+	return false
+}
+
+// classMinutes returns the length of a class, or 0 if its times cannot be parsed
+func classMinutes(startTime, endTime string) int {
+	start, errStart := optimization.ParseClock(startTime)
+	end, errEnd := optimization.ParseClock(endTime)
+	if errStart != nil || errEnd != nil || end < start {
+		return 0
+	}
+	return end - start
+}
+
+// getCurriculumCohorts treats the active students of the timetable's program
+// who are in this semester as one cohort. Its courses are the compulsory
+// (non-elective) ones that at least half of those students are enrolled in
+// this semester, so only courses the cohort actually takes together are
+// linked. Without a program, students or enrollments there is no cohort.
+func getCurriculumCohorts(timetable models.TimetableTemplate, courses []models.Course) []optimization.CourseCohort {
+	if timetable.ProgramID == nil {
+		return nil
+	}
+
+	var program models.Program
+	if err := database.DB.First(&program, *timetable.ProgramID).Error; err != nil {
+		return nil
+	}
+
+	var semester models.Semester
+	if err := database.DB.First(&semester, timetable.SemesterID).Error; err != nil {
+		return nil
+	}
+
+	var studentIDs []uuid.UUID
+	database.DB.Model(&models.Student{}).
+		Where("program_id = ? AND current_semester = ? AND is_active = ?", program.ID, semester.SemesterNumber, true).
+		Pluck("id", &studentIDs)
+	if len(studentIDs) == 0 {
+		return nil
+	}
+
+	var enrollments []models.StudentEnrollment
+	database.DB.
+		Where("semester_id = ? AND student_id IN ? AND status <> ?", semester.ID, studentIDs, "DROPPED").
+		Find(&enrollments)
+
+	takers := make(map[uuid.UUID]int)
+	for _, enrollment := range enrollments {
+		takers[enrollment.CourseID]++
+	}
+
+	cohort := optimization.CourseCohort{
+		GroupID:  fmt.Sprintf("%s-S%d", program.Code, semester.SemesterNumber),
+		Students: len(studentIDs),
+	}
 	for _, course := range courses {
-		if course.CourseType == "THEORY" {
-			ids = append(ids, course.ID.String())
+		if course.Category != nil && course.Category.Code == "ELECTIVE" {
+			continue
 		}
+		if 2*takers[course.ID] < len(studentIDs) {
+			continue
+		}
+		cohort.CourseIDs = append(cohort.CourseIDs, course.ID)
+	}
+
+	if len(cohort.CourseIDs) == 0 {
+		return nil
 	}
-	return ids
+	return []optimization.CourseCohort{cohort}
 }
 
-func saveSolutionToDatabase(timetableID uuid.UUID, solution *optimization.Solution) error {
+// insertBatchSize is how many rows each batched insert of a generated
+// solution carries
+const insertBatchSize = 200
+
+// saveSolutionToDatabase replaces the timetable's scheduled classes with the
+// solution's assignments. It is meant to run inside a transaction.
+func saveSolutionToDatabase(tx *gorm.DB, timetable models.TimetableTemplate, courses []models.Course, solution *optimization.Solution) error {
 	// Clear existing scheduled classes
-	database.DB.Where("timetable_id = ?", timetableID).Delete(&models.ScheduledClass{})
+	if err := tx.Where("timetable_id = ?", timetable.ID).Delete(&models.ScheduledClass{}).Error; err != nil {
+		return err
+	}
+
+	courseTypes := make(map[uuid.UUID]string, len(courses))
+	for _, course := range courses {
+		courseTypes[course.ID] = course.CourseType
+	}
+
+	// Insert in a stable order
+	keys := make([]string, 0, len(solution.Schedule))
+	for key := range solution.Schedule {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	classes := make([]models.ScheduledClass, 0, len(keys))
+	for _, key := range keys {
+		assignment := solution.Schedule[key]
+		facultyID := assignment.FacultyID
+		roomID := assignment.RoomID
 
-	// Save new schedule
-	for _, assignment := range solution.Schedule {
 		class := models.ScheduledClass{
-			TimetableID: timetableID,
+			TimetableID: timetable.ID,
 			CourseID:    assignment.CourseID,
-			FacultyID:   &assignment.FacultyID,
-			RoomID:      &assignment.RoomID,
+			FacultyID:   &facultyID,
+			RoomID:      &roomID,
 			DayOfWeek:   assignment.DayOfWeek,
 			StartTime:   assignment.StartTime,
 			EndTime:     assignment.EndTime,
 			TimeSlotID:  assignment.TimeSlot.ID,
+			SemesterID:  timetable.SemesterID,
+			IsLab:       courseTypes[assignment.CourseID] == "LAB",
+			// BatchNumber stays unset: the engine schedules every meeting for
+			// the whole class and does not split students into batches
 		}
 
-		if err := database.DB.Create(&class).Error; err != nil {
-			return err
+		classes = append(classes, class)
+	}
+
+	if len(classes) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&classes, insertBatchSize).Error
+}
+
+// saveConflictLogs replaces the timetable's unresolved conflict logs with the
+// hard violations of the new solution. Soft violations are preferences, not
+// conflicts, so they are only returned to the caller. It is meant to run
+// inside a transaction.
+func saveConflictLogs(tx *gorm.DB, timetableID uuid.UUID, violations []optimization.Violation) error {
+	if err := tx.Where("timetable_id = ? AND is_resolved = ?", timetableID, false).Delete(&models.ConflictLog{}).Error; err != nil {
+		return err
+	}
+
+	logs := []models.ConflictLog{}
+	for _, violation := range violations {
+		if !violation.Hard {
+			continue
 		}
+
+		logs = append(logs, models.ConflictLog{
+			TimetableID:  timetableID,
+			ConflictType: violation.Type,
+			Description:  violation.Description,
+			Severity:     violation.Severity,
+			AffectedEntities: map[string]interface{}{
+				"constraint":        violation.Constraint,
+				"assignment_keys":   violation.Keys,
+				"faculty_ids":       violation.FacultyIDs,
+				"room_ids":          violation.RoomIDs,
+				"course_ids":        violation.CourseIDs,
+				"student_group_ids": violation.StudentGroupIDs,
+				"students_affected": violation.StudentsAffected,
+			},
+		})
 	}
 
-	return nil
+	if len(logs) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&logs, insertBatchSize).Error
 }
 
 func timePtr(t time.Time) *time.Time {
@@ -681,13 +1412,6 @@ func strPtr(s string) *string {
 	return &s
 }
 
-// GetGenerationStatus retrieves the status of timetable generation
-func GetGenerationStatus(c *fiber.Ctx) error {
-	return c.JSON(fiber.Map{
-		"message": "Get generation status - to be implemented",
-	})
-}
-
 // ResolveConflict marks a conflict as resolved
 func ResolveConflict(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
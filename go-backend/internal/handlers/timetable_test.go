@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+	"github.com/yourusername/timetable-scheduler/internal/optimization"
+)
+
+func TestTimetableRoutes(t *testing.T) {
+	app := newTestApp(t)
+	semester := seedSemester(t)
+
+	call(t, app, "POST", "/api/v1/timetables", fiber.Map{"name": "No semester"}, 400)
+
+	timetable := createTimetable(t, app, semester.ID)
+	if timetable.Status != "DRAFT" {
+		t.Fatalf("new timetable has status %s, want DRAFT", timetable.Status)
+	}
+	path := "/api/v1/timetables/" + timetable.ID.String()
+
+	// Five days of eight default slots
+	response := call(t, app, "GET", path, nil, 200)
+	var fetched models.TimetableTemplate
+	decode(t, response.Data, &fetched)
+	if fetched.ID != timetable.ID || len(fetched.TimeSlots) != 40 {
+		t.Fatalf("fetched timetable %s has %d time slots, want %s with 40", fetched.ID, len(fetched.TimeSlots), timetable.ID)
+	}
+
+	// Publishing only goes through the publish endpoint
+	response = call(t, app, "PUT", path, fiber.Map{"name": "Fall 2025 - CS (revised)", "status": "PUBLISHED"}, 200)
+	var updated models.TimetableTemplate
+	decode(t, response.Data, &updated)
+	if updated.ID != timetable.ID || updated.Name != "Fall 2025 - CS (revised)" || updated.Status != "DRAFT" {
+		t.Fatalf("updated timetable is %s %q with status %s", updated.ID, updated.Name, updated.Status)
+	}
+
+	call(t, app, "DELETE", path, nil, 200)
+	call(t, app, "GET", path, nil, 404)
+	call(t, app, "GET", "/api/v1/timetables/"+uuid.NewString(), nil, 404)
+}
+
+func TestScheduledClassRoutes(t *testing.T) {
+	app := newTestApp(t)
+	semester := seedSemester(t)
+	timetable := createTimetable(t, app, semester.ID)
+	course := seedCourse(t, "CS101", "THEORY")
+	other := seedCourse(t, "CS102", "THEORY")
+	faculty := seedFaculty(t, "EMP001")
+	room := seedRoom(t, "A101", "CLASSROOM", 60)
+	slot := timeSlot(t, timetable.ID, 1, "09:00")
+	path := "/api/v1/timetables/" + timetable.ID.String() + "/classes"
+
+	class := func(courseID uuid.UUID, start, end string) fiber.Map {
+		return fiber.Map{
+			"course_id":    courseID,
+			"faculty_id":   faculty.ID,
+			"room_id":      room.ID,
+			"time_slot_id": slot.ID,
+			"semester_id":  semester.ID,
+			"day_of_week":  1,
+			"start_time":   start,
+			"end_time":     end,
+		}
+	}
+
+	response := call(t, app, "POST", path, class(course.ID, "09:00", "10:00"), 201)
+	var created models.ScheduledClass
+	decode(t, response.Data, &created)
+	if created.Course.Code != "CS101" || created.Room == nil || created.Room.RoomNumber != "A101" {
+		t.Fatalf("created class has course %q and room %+v", created.Course.Code, created.Room)
+	}
+	classPath := "/api/v1/timetables/classes/" + created.ID.String()
+
+	// The same faculty member and room cannot take another class at that time
+	response = call(t, app, "POST", path, class(other.ID, "09:30", "10:30"), 409)
+	types := make(map[string]bool)
+	for _, conflict := range response.Conflicts {
+		types[conflict.Type] = true
+	}
+	if !types[optimization.ConflictFacultyDoubleBooking] || !types[optimization.ConflictRoomDoubleBooking] {
+		t.Fatalf("clashing class reported conflicts %+v, want faculty and room double booking", response.Conflicts)
+	}
+
+	response = call(t, app, "GET", path, nil, 200)
+	if response.Count != 1 {
+		t.Fatalf("timetable has %d classes, want 1", response.Count)
+	}
+
+	response = call(t, app, "PUT", classPath, class(course.ID, "10:00", "11:00"), 200)
+	var moved models.ScheduledClass
+	decode(t, response.Data, &moved)
+	if moved.ID != created.ID || moved.StartTime != "10:00" || moved.EndTime != "11:00" {
+		t.Fatalf("moved class %s runs %s-%s, want %s at 10:00-11:00", moved.ID, moved.StartTime, moved.EndTime, created.ID)
+	}
+
+	// The old time is free again; a class without a semester takes the
+	// timetable's
+	freed := class(other.ID, "09:00", "10:00")
+	delete(freed, "semester_id")
+	response = call(t, app, "POST", path, freed, 201)
+	var added models.ScheduledClass
+	decode(t, response.Data, &added)
+	if added.SemesterID != semester.ID {
+		t.Fatalf("class added without a semester has semester %s, want %s", added.SemesterID, semester.ID)
+	}
+
+	call(t, app, "DELETE", classPath, nil, 200)
+	call(t, app, "DELETE", classPath, nil, 404)
+	call(t, app, "PUT", classPath, class(course.ID, "10:00", "11:00"), 404)
+
+	response = call(t, app, "GET", path, nil, 200)
+	if response.Count != 1 {
+		t.Fatalf("timetable has %d classes after the delete, want 1", response.Count)
+	}
+}
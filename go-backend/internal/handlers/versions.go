@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/database"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// Publishing a timetable snapshots its scheduled classes as a new, immutable
+// TimetableVersion. The scheduled_classes rows stay the working copy: once
+// they change after a publish the timetable is a draft of the next version
+// again, while the published versions record what was in force and when.
+
+// publishVersion snapshots the timetable's current schedule as its next
+// version and ends the version in force before it. It is meant to run inside
+// a transaction.
+func publishVersion(tx *gorm.DB, timetable models.TimetableTemplate, publishedAt time.Time, publishedBy uuid.UUID) (*models.TimetableVersion, error) {
+	classes, err := snapshotClasses(tx, timetable.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest int
+	err = tx.Model(&models.TimetableVersion{}).
+		Where("timetable_id = ?", timetable.ID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// The version in force so far ends when this one starts
+	err = tx.Model(&models.TimetableVersion{}).
+		Where("timetable_id = ? AND superseded_at IS NULL", timetable.ID).
+		Update("superseded_at", publishedAt).Error
+	if err != nil {
+		return nil, err
+	}
+
+	version := &models.TimetableVersion{
+		TimetableID: timetable.ID,
+		Version:     latest + 1,
+		Name:        timetable.Name,
+		PublishedAt: publishedAt,
+		ClassCount:  len(classes),
+		Classes:     classes,
+	}
+	if publishedBy != uuid.Nil {
+		version.PublishedBy = &publishedBy
+	}
+
+	if err := tx.Create(version).Error; err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// snapshotClasses copies a timetable's scheduled classes, with the names of
+// their course, faculty and room, in day and time order
+func snapshotClasses(tx *gorm.DB, timetableID uuid.UUID) ([]models.VersionClass, error) {
+	var scheduled []models.ScheduledClass
+	err := tx.
+		Preload("Course").
+		Preload("Faculty").
+		Preload("Room").
+		Where("timetable_id = ?", timetableID).
+		Order("day_of_week, start_time").
+		Find(&scheduled).Error
+	if err != nil {
+		return nil, err
+	}
+
+	classes := make([]models.VersionClass, 0, len(scheduled))
+	for _, class := range scheduled {
+		snapshot := models.VersionClass{
+			CourseID:    class.CourseID,
+			CourseCode:  class.Course.Code,
+			CourseName:  class.Course.Name,
+			FacultyID:   class.FacultyID,
+			RoomID:      class.RoomID,
+			TimeSlotID:  class.TimeSlotID,
+			DayOfWeek:   class.DayOfWeek,
+			StartTime:   class.StartTime,
+			EndTime:     class.EndTime,
+			SemesterID:  class.SemesterID,
+			IsLab:       class.IsLab,
+			IsTutorial:  class.IsTutorial,
+			BatchNumber: class.BatchNumber,
+		}
+		if class.Faculty != nil {
+			snapshot.FacultyName = class.Faculty.FirstName + " " + class.Faculty.LastName
+		}
+		if class.Room != nil {
+			snapshot.RoomNumber = class.Room.RoomNumber
+		}
+		classes = append(classes, snapshot)
+	}
+	return classes, nil
+}
+
+// startDraftVersion marks a published timetable as a draft once its working
+// schedule changes. The published versions are left as they are.
+func startDraftVersion(tx *gorm.DB, timetableID uuid.UUID) error {
+	return tx.Model(&models.TimetableTemplate{}).
+		Where("id = ? AND status = ?", timetableID, "PUBLISHED").
+		Update("status", "DRAFT").Error
+}
+
+// generatedStatus is the status of a timetable once a generated schedule
+// replaces its classes. A published timetable becomes a draft of its next
+// version, as with any other change to its schedule.
+func generatedStatus(previousStatus string) string {
+	if previousStatus == "PUBLISHED" {
+		return "DRAFT"
+	}
+	return "GENERATED"
+}
+
+// GetTimetableVersions lists a timetable's published versions, newest first,
+// without their classes
+func GetTimetableVersions(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	timetableID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	var versions []models.TimetableVersion
+	result := database.DB.
+		Omit("classes").
+		Where("timetable_id = ?", timetableID).
+		Order("version DESC").
+		Find(&versions)
+
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to fetch versions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data":  versions,
+		"count": len(versions),
+	})
+}
+
+// GetTimetableVersion retrieves one published version with its classes
+func GetTimetableVersion(c *fiber.Ctx) error {
+	version, ok := findVersion(c)
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Version not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": version,
+	})
+}
+
+// GetVersionInForce retrieves the version that was in force at the time given
+// by the at query parameter, or now. at is an RFC 3339 time or a date; a date
+// means the end of that day, UTC.
+func GetVersionInForce(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	timetableID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		if at, err = time.Parse(time.RFC3339, value); err != nil {
+			date, dateErr := time.Parse("2006-01-02", value)
+			if dateErr != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error": "at must be an RFC 3339 time or a YYYY-MM-DD date",
+				})
+			}
+			at = date.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+
+	var version models.TimetableVersion
+	err = database.DB.
+		Where("timetable_id = ? AND published_at <= ? AND (superseded_at IS NULL OR superseded_at > ?)", timetableID, at, at).
+		First(&version).Error
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "No version of this timetable was in force at that time",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": version,
+	})
+}
+
+// RollbackTimetableVersion replaces the timetable's working schedule with the
+// classes of a published version. The result is a draft; publishing it puts
+// it in force as a new version.
+func RollbackTimetableVersion(c *fiber.Ctx) error {
+	version, ok := findVersion(c)
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Version not found",
+		})
+	}
+
+	var timetable models.TimetableTemplate
+	if err := database.DB.First(&timetable, version.TimetableID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Timetable not found",
+		})
+	}
+
+	if timetable.Status == "GENERATING" {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Timetable is being generated",
+		})
+	}
+
+	classes := make([]models.ScheduledClass, 0, len(version.Classes))
+	for _, snapshot := range version.Classes {
+		classes = append(classes, models.ScheduledClass{
+			TimetableID: timetable.ID,
+			CourseID:    snapshot.CourseID,
+			FacultyID:   snapshot.FacultyID,
+			RoomID:      snapshot.RoomID,
+			TimeSlotID:  snapshot.TimeSlotID,
+			DayOfWeek:   snapshot.DayOfWeek,
+			StartTime:   snapshot.StartTime,
+			EndTime:     snapshot.EndTime,
+			SemesterID:  snapshot.SemesterID,
+			IsLab:       snapshot.IsLab,
+			IsTutorial:  snapshot.IsTutorial,
+			BatchNumber: snapshot.BatchNumber,
+		})
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("timetable_id = ?", timetable.ID).Delete(&models.ScheduledClass{}).Error; err != nil {
+			return err
+		}
+
+		// Conflicts found in the replaced schedule no longer apply
+		if err := tx.Where("timetable_id = ? AND is_resolved = ?", timetable.ID, false).Delete(&models.ConflictLog{}).Error; err != nil {
+			return err
+		}
+
+		if len(classes) > 0 {
+			if err := tx.CreateInBatches(&classes, insertBatchSize).Error; err != nil {
+				if isForeignKeyViolation(err) {
+					return errVersionOutdated
+				}
+				return err
+			}
+		}
+
+		return tx.Model(&models.TimetableTemplate{}).
+			Where("id = ?", timetable.ID).
+			Update("status", "DRAFT").Error
+	})
+	if errors.Is(err, errVersionOutdated) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "The version refers to courses, faculty, rooms or time slots that no longer exist",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to roll back timetable",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Timetable rolled back to version " + strconv.Itoa(version.Version) + " as a draft",
+		"data":    classes,
+	})
+}
+
+// errVersionOutdated is returned when a version's classes can no longer be
+// restored
+var errVersionOutdated = errors.New("version refers to records that no longer exist")
+
+// isForeignKeyViolation reports whether Postgres refused a row because a
+// record it refers to does not exist (SQLSTATE 23503)
+func isForeignKeyViolation(err error) bool {
+	if translator, ok := database.DB.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	return errors.Is(err, gorm.ErrForeignKeyViolated)
+}
+
+// findVersion loads the version named by the :id and :version route
+// parameters
+func findVersion(c *fiber.Ctx) (*models.TimetableVersion, bool) {
+	timetableID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, false
+	}
+	number, err := strconv.Atoi(c.Params("version"))
+	if err != nil {
+		return nil, false
+	}
+
+	var version models.TimetableVersion
+	if err := database.DB.Where("timetable_id = ? AND version = ?", timetableID, number).First(&version).Error; err != nil {
+		return nil, false
+	}
+	return &version, true
+}
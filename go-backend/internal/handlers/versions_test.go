@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestTimetableVersionRoutes(t *testing.T) {
+	app := newTestApp(t)
+	semester := seedSemester(t)
+	timetable := createTimetable(t, app, semester.ID)
+	course := seedCourse(t, "CS101", "THEORY")
+	faculty := seedFaculty(t, "EMP001")
+	room := seedRoom(t, "A101", "CLASSROOM", 60)
+	slot := timeSlot(t, timetable.ID, 2, "11:00")
+	path := "/api/v1/timetables/" + timetable.ID.String()
+
+	response := call(t, app, "POST", path+"/classes", fiber.Map{
+		"course_id":    course.ID,
+		"faculty_id":   faculty.ID,
+		"room_id":      room.ID,
+		"time_slot_id": slot.ID,
+		"semester_id":  semester.ID,
+		"day_of_week":  2,
+		"start_time":   "11:00",
+		"end_time":     "12:00",
+	}, 201)
+	var class models.ScheduledClass
+	decode(t, response.Data, &class)
+
+	call(t, app, "GET", path+"/versions/in-force", nil, 404)
+
+	response = call(t, app, "POST", path+"/publish", nil, 200)
+	if response.Version != 1 {
+		t.Fatalf("first publish made version %d, want 1", response.Version)
+	}
+	call(t, app, "POST", path+"/publish", nil, 409)
+
+	response = call(t, app, "GET", path+"/versions", nil, 200)
+	if response.Count != 1 {
+		t.Fatalf("timetable has %d versions, want 1", response.Count)
+	}
+
+	response = call(t, app, "GET", path+"/versions/1", nil, 200)
+	var version models.TimetableVersion
+	decode(t, response.Data, &version)
+	if version.ClassCount != 1 || len(version.Classes) != 1 {
+		t.Fatalf("version 1 has %d classes (class_count %d), want 1", len(version.Classes), version.ClassCount)
+	}
+	if snapshot := version.Classes[0]; snapshot.CourseCode != "CS101" || snapshot.RoomNumber != "A101" || snapshot.FacultyName != "Faculty EMP001" {
+		t.Fatalf("version 1 recorded %+v", snapshot)
+	}
+	call(t, app, "GET", path+"/versions/2", nil, 404)
+
+	response = call(t, app, "GET", path+"/versions/in-force", nil, 200)
+	decode(t, response.Data, &version)
+	if version.Version != 1 {
+		t.Fatalf("version %d is in force, want 1", version.Version)
+	}
+	call(t, app, "GET", path+"/versions/in-force?at=2000-01-01", nil, 404)
+	call(t, app, "GET", path+"/versions/in-force?at=yesterday", nil, 400)
+
+	// Editing the published timetable starts a draft of the next version
+	call(t, app, "DELETE", "/api/v1/timetables/classes/"+class.ID.String(), nil, 200)
+	response = call(t, app, "GET", path, nil, 200)
+	var draft models.TimetableTemplate
+	decode(t, response.Data, &draft)
+	if draft.Status != "DRAFT" || len(draft.ScheduledClasses) != 0 {
+		t.Fatalf("edited timetable has status %s and %d classes, want a DRAFT without classes", draft.Status, len(draft.ScheduledClasses))
+	}
+
+	response = call(t, app, "POST", path+"/versions/1/rollback", nil, 200)
+	var restored []models.ScheduledClass
+	decode(t, response.Data, &restored)
+	if len(restored) != 1 || restored[0].CourseID != course.ID {
+		t.Fatalf("rollback restored %+v, want the CS101 class", restored)
+	}
+	call(t, app, "POST", path+"/versions/3/rollback", nil, 404)
+
+	response = call(t, app, "POST", path+"/publish", nil, 200)
+	if response.Version != 2 {
+		t.Fatalf("second publish made version %d, want 2", response.Version)
+	}
+
+	response = call(t, app, "GET", path+"/versions/1", nil, 200)
+	decode(t, response.Data, &version)
+	if version.SupersededAt == nil {
+		t.Fatal("version 1 is still in force after version 2 was published")
+	}
+}
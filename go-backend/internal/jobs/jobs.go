@@ -0,0 +1,248 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/optimization"
+)
+
+// Job statuses
+const (
+	StatusRunning   = "RUNNING"
+	StatusCompleted = "COMPLETED"
+	StatusFailed    = "FAILED"
+	StatusCancelled = "CANCELLED"
+)
+
+// ErrAlreadyRunning is returned when a timetable already has a job in progress
+var ErrAlreadyRunning = errors.New("a generation job is already running for this timetable")
+
+// RunFunc does the work of a job. It must stop promptly once ctx is done.
+type RunFunc func(ctx context.Context, job *Job) (interface{}, error)
+
+// Job is one background timetable generation
+type Job struct {
+	ID          uuid.UUID
+	TimetableID uuid.UUID
+
+	mu         sync.RWMutex
+	status     string
+	progress   optimization.Progress
+	result     interface{}
+	err        string
+	startedAt  time.Time
+	finishedAt *time.Time
+	cancel     context.CancelFunc
+	done       chan struct{}
+	listeners  map[chan optimization.Progress]struct{}
+}
+
+// Snapshot is the JSON view of a job at a point in time
+type Snapshot struct {
+	ID          uuid.UUID             `json:"job_id"`
+	TimetableID uuid.UUID             `json:"timetable_id"`
+	Status      string                `json:"status"`
+	Progress    optimization.Progress `json:"progress"`
+	Result      interface{}           `json:"result,omitempty"`
+	Error       string                `json:"error,omitempty"`
+	StartedAt   time.Time             `json:"started_at"`
+	FinishedAt  *time.Time            `json:"finished_at,omitempty"`
+}
+
+// SetProgress records the latest progress of the search and passes it on to
+// subscribers. A subscriber that is not keeping up misses updates rather than
+// slowing the search down.
+func (j *Job) SetProgress(progress optimization.Progress) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress = progress
+
+	for listener := range j.listeners {
+		select {
+		case listener <- progress:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of progress updates and a function to stop
+// receiving them. The channel is not closed when the job ends; wait on Done.
+func (j *Job) Subscribe() (<-chan optimization.Progress, func()) {
+	listener := make(chan optimization.Progress, 64)
+
+	j.mu.Lock()
+	j.listeners[listener] = struct{}{}
+	j.mu.Unlock()
+
+	return listener, func() {
+		j.mu.Lock()
+		delete(j.listeners, listener)
+		j.mu.Unlock()
+	}
+}
+
+// Snapshot returns the job's current state
+func (j *Job) Snapshot() Snapshot {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return Snapshot{
+		ID:          j.ID,
+		TimetableID: j.TimetableID,
+		Status:      j.status,
+		Progress:    j.progress,
+		Result:      j.result,
+		Error:       j.err,
+		StartedAt:   j.startedAt,
+		FinishedAt:  j.finishedAt,
+	}
+}
+
+// Running reports whether the job has not finished yet
+func (j *Job) Running() bool {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.status == StatusRunning
+}
+
+// Done is closed when the job finishes
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// finish records how the job ended
+func (j *Job) finish(ctx context.Context, result interface{}, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	j.finishedAt = &now
+
+	switch {
+	case ctx.Err() != nil:
+		j.status = StatusCancelled
+	case err != nil:
+		j.status = StatusFailed
+		j.err = err.Error()
+	default:
+		j.status = StatusCompleted
+		j.result = result
+	}
+}
+
+// Manager runs generation jobs in the background, at most one per timetable
+type Manager struct {
+	mu          sync.Mutex
+	jobs        map[uuid.UUID]*Job
+	byTimetable map[uuid.UUID]*Job
+	wg          sync.WaitGroup
+}
+
+// NewManager creates an empty job manager
+func NewManager() *Manager {
+	return &Manager{
+		jobs:        make(map[uuid.UUID]*Job),
+		byTimetable: make(map[uuid.UUID]*Job),
+	}
+}
+
+// Start runs fn in the background for the given timetable. Only the latest job
+// of each timetable is kept once it has finished.
+func (m *Manager) Start(timetableID uuid.UUID, fn RunFunc) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if previous, ok := m.byTimetable[timetableID]; ok {
+		if previous.Running() {
+			return nil, ErrAlreadyRunning
+		}
+		delete(m.jobs, previous.ID)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		ID:          uuid.New(),
+		TimetableID: timetableID,
+		status:      StatusRunning,
+		startedAt:   time.Now(),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+		listeners:   make(map[chan optimization.Progress]struct{}),
+	}
+	m.jobs[job.ID] = job
+	m.byTimetable[timetableID] = job
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer close(job.done)
+		defer cancel()
+
+		var result interface{}
+		var err error
+		func() {
+			// A panic in the optimizer must fail the job, not the server
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("generation panicked: %v", r)
+				}
+			}()
+			result, err = fn(ctx, job)
+		}()
+		job.finish(ctx, result, err)
+	}()
+
+	return job, nil
+}
+
+// Get returns a job by ID
+func (m *Manager) Get(id uuid.UUID) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// Latest returns the most recent job for a timetable
+func (m *Manager) Latest(timetableID uuid.UUID) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.byTimetable[timetableID]
+	return job, ok
+}
+
+// Cancel asks a running job to stop. It reports false if the job had already
+// finished.
+func (m *Manager) Cancel(job *Job) bool {
+	if !job.Running() {
+		return false
+	}
+	job.cancel()
+	return true
+}
+
+// Shutdown cancels every running job and waits for them to clean up, or for
+// ctx to expire
+func (m *Manager) Shutdown(ctx context.Context) {
+	m.mu.Lock()
+	for _, job := range m.jobs {
+		job.cancel()
+	}
+	m.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+	}
+}
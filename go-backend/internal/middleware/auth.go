@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// User roles
+const (
+	RoleAdmin              = "admin"
+	RoleScheduler          = "scheduler"
+	RoleDepartmentHead     = "department_head"
+	RoleProgramCoordinator = "program_coordinator"
+	RoleFaculty            = "faculty"
+	RoleStudent            = "student"
+)
+
+var knownRoles = map[string]bool{
+	RoleAdmin:              true,
+	RoleScheduler:          true,
+	RoleDepartmentHead:     true,
+	RoleProgramCoordinator: true,
+	RoleFaculty:            true,
+	RoleStudent:            true,
+}
+
+// Keys of the authenticated user in the Fiber context
+const (
+	userIDKey = "user_id"
+	roleKey   = "role"
+	scopeKey  = "scope"
+)
+
+// Claims are the JWT claims the API relies on. The subject is the auth user
+// ID. The role is read from app_metadata, where Supabase keeps
+// application roles, falling back to a top-level role claim. Department
+// heads and program coordinators also carry the department or program they
+// are responsible for.
+type Claims struct {
+	Role        string `json:"role"`
+	AppMetadata struct {
+		Role         string `json:"role"`
+		DepartmentID string `json:"department_id"`
+		ProgramID    string `json:"program_id"`
+	} `json:"app_metadata"`
+	jwt.RegisteredClaims
+}
+
+// Scope is the part of the institution a user is limited to. A nil ID
+// places no limit at that level.
+type Scope struct {
+	DepartmentID *uuid.UUID
+	ProgramID    *uuid.UUID
+}
+
+// scope returns the scope of the claims' role, or an error if a scoped role
+// does not say which department or program it is for
+func (c *Claims) scope(role string) (Scope, error) {
+	switch role {
+	case RoleDepartmentHead:
+		departmentID, err := uuid.Parse(c.AppMetadata.DepartmentID)
+		if err != nil {
+			return Scope{}, errors.New("Department head token has no valid department")
+		}
+		return Scope{DepartmentID: &departmentID}, nil
+	case RoleProgramCoordinator:
+		programID, err := uuid.Parse(c.AppMetadata.ProgramID)
+		if err != nil {
+			return Scope{}, errors.New("Program coordinator token has no valid program")
+		}
+		return Scope{ProgramID: &programID}, nil
+	}
+	return Scope{}, nil
+}
+
+// role returns the application role carried by the claims
+func (c *Claims) role() string {
+	if c.AppMetadata.Role != "" {
+		return c.AppMetadata.Role
+	}
+	return c.Role
+}
+
+// Authenticate validates the HS256 bearer token of each request and stores
+// the user ID and role in the context. The token may also be passed as the
+// access_token query parameter, for clients such as EventSource that cannot
+// set headers.
+func Authenticate(secret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenString := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if tokenString == "" {
+			tokenString = c.Query("access_token")
+		}
+		if tokenString == "" {
+			return c.Status(401).JSON(fiber.Map{
+				"error": "Missing authentication token",
+			})
+		}
+
+		claims := &Claims{}
+		_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte(secret), nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{
+				"error": "Invalid or expired authentication token",
+			})
+		}
+
+		userID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{
+				"error": "Authentication token has no valid user",
+			})
+		}
+
+		role := claims.role()
+		if !knownRoles[role] {
+			return c.Status(403).JSON(fiber.Map{
+				"error": "Unknown user role",
+			})
+		}
+
+		scope, err := claims.scope(role)
+		if err != nil {
+			return c.Status(403).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		c.Locals(userIDKey, userID)
+		c.Locals(roleKey, role)
+		c.Locals(scopeKey, scope)
+		return c.Next()
+	}
+}
+
+// RequireRoles only lets users with one of the given roles through. Admins
+// are always allowed.
+func RequireRoles(roles ...string) fiber.Handler {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		role := Role(c)
+		if role != RoleAdmin && !allowed[role] {
+			return c.Status(403).JSON(fiber.Map{
+				"error": "Insufficient permissions",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// UserID returns the authenticated user's ID, or uuid.Nil when the request
+// is not authenticated
+func UserID(c *fiber.Ctx) uuid.UUID {
+	userID, _ := c.Locals(userIDKey).(uuid.UUID)
+	return userID
+}
+
+// Role returns the authenticated user's role, or "" when the request is not
+// authenticated
+func Role(c *fiber.Ctx) string {
+	role, _ := c.Locals(roleKey).(string)
+	return role
+}
+
+// ScopeOf returns the department or program the authenticated user is
+// limited to
+func ScopeOf(c *fiber.Ctx) Scope {
+	scope, _ := c.Locals(scopeKey).(Scope)
+	return scope
+}
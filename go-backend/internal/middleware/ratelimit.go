@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Rate limit response headers
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// RateLimitConfig configures a fixed window rate limiter
+type RateLimitConfig struct {
+	// Max is the number of requests a key may make per window. Zero or less
+	// turns the limiter off.
+	Max int
+	// Window is the length of a window
+	Window time.Duration
+	// Key returns the key requests are counted under, such as ByIP or ByUser
+	Key func(c *fiber.Ctx) string
+	// Next skips the limiter for requests it returns true for
+	Next func(c *fiber.Ctx) bool
+}
+
+// ByIP counts requests per client IP
+func ByIP(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// ByUser counts requests per authenticated user, falling back to the client
+// IP for requests without a user
+func ByUser(c *fiber.Ctx) string {
+	if userID := UserID(c); userID != uuid.Nil {
+		return "user:" + userID.String()
+	}
+	return ByIP(c)
+}
+
+type rateWindow struct {
+	hits  int
+	reset time.Time
+}
+
+// RateLimit allows each key Max requests per window and rejects the rest
+// with 429 Too Many Requests, which the app's error handler renders.
+// Responses carry the X-RateLimit headers; when several limiters apply to a
+// request the headers describe whichever has the fewest requests left.
+func RateLimit(cfg RateLimitConfig) fiber.Handler {
+	var (
+		mu        sync.Mutex
+		windows   = make(map[string]*rateWindow)
+		nextSweep time.Time
+	)
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Max <= 0 || (cfg.Next != nil && cfg.Next(c)) {
+			return c.Next()
+		}
+
+		key := cfg.Key(c)
+		now := time.Now()
+
+		mu.Lock()
+		// Drop windows that have run out so idle keys do not pile up
+		if now.After(nextSweep) {
+			for k, w := range windows {
+				if !now.Before(w.reset) {
+					delete(windows, k)
+				}
+			}
+			nextSweep = now.Add(cfg.Window)
+		}
+
+		w, ok := windows[key]
+		if !ok || !now.Before(w.reset) {
+			w = &rateWindow{reset: now.Add(cfg.Window)}
+			windows[key] = w
+		}
+		w.hits++
+		hits, reset := w.hits, w.reset
+		mu.Unlock()
+
+		remaining := cfg.Max - hits
+		if remaining < 0 {
+			remaining = 0
+		}
+		resetIn := int(reset.Sub(now).Round(time.Second) / time.Second)
+		if resetIn < 1 {
+			resetIn = 1
+		}
+
+		if current, err := strconv.Atoi(c.GetRespHeader(HeaderRateLimitRemaining)); err != nil || remaining <= current {
+			c.Set(HeaderRateLimitLimit, strconv.Itoa(cfg.Max))
+			c.Set(HeaderRateLimitRemaining, strconv.Itoa(remaining))
+			c.Set(HeaderRateLimitReset, strconv.Itoa(resetIn))
+		}
+
+		if hits > cfg.Max {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(resetIn))
+			return fiber.NewError(fiber.StatusTooManyRequests,
+				fmt.Sprintf("Rate limit exceeded, try again in %d seconds", resetIn))
+		}
+
+		return c.Next()
+	}
+}
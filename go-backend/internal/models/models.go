@@ -113,6 +113,12 @@ type Course struct {
 	Prerequisites []string   `json:"prerequisites" gorm:"type:text[]"`
 	IsActive      bool       `json:"is_active" gorm:"default:true;index"`
 
+	// Equipment the assigned room must provide
+	RequiresProjector  bool `json:"requires_projector" gorm:"default:false"`
+	RequiresComputer   bool `json:"requires_computer" gorm:"default:false"`
+	RequiresSmartBoard bool `json:"requires_smart_board" gorm:"default:false"`
+	RequiresAC         bool `json:"requires_ac" gorm:"default:false"`
+
 	// Relations
 	Department             *Department              `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
 	Category               *CourseCategory          `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
@@ -238,6 +244,7 @@ type TimetableTemplate struct {
 	GenerationStartTime  *time.Time `json:"generation_start_time"`
 	GenerationEndTime    *time.Time `json:"generation_end_time"`
 	AlgorithmUsed        *string    `json:"algorithm_used"`
+	GenerationSeed       *int64     `json:"generation_seed"`
 	IsPublished          bool       `json:"is_published" gorm:"default:false"`
 	PublishedAt          *time.Time `json:"published_at"`
 	CreatedBy            *uuid.UUID `json:"created_by"` // Links to auth.users
@@ -295,9 +302,10 @@ type TimetableConstraint struct {
 	ID              uuid.UUID              `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
 	TimetableID     uuid.UUID              `json:"timetable_id" gorm:"not null;index"`
 	ConstraintType  string                 `json:"constraint_type" gorm:"not null"`
-	ConstraintData  map[string]interface{} `json:"constraint_data" gorm:"type:jsonb;not null"`
+	ConstraintData  map[string]interface{} `json:"constraint_data" gorm:"type:jsonb;serializer:json;not null"`
 	Priority        int                    `json:"priority" gorm:"default:1;check:priority BETWEEN 1 AND 10"`
 	IsHardConstraint bool                  `json:"is_hard_constraint" gorm:"default:false"`
+	IsDisabled      bool                   `json:"is_disabled" gorm:"default:false"` // switches off a default constraint
 	CreatedAt       time.Time              `json:"created_at" gorm:"autoCreateTime"`
 
 	// Relations
@@ -311,7 +319,7 @@ type ConflictLog struct {
 	ConflictType     string                 `json:"conflict_type" gorm:"not null"`
 	Description      string                 `json:"description" gorm:"type:text;not null"`
 	Severity         string                 `json:"severity" gorm:"default:'MEDIUM';check:severity IN ('LOW','MEDIUM','HIGH','CRITICAL');index"`
-	AffectedEntities map[string]interface{} `json:"affected_entities" gorm:"type:jsonb"`
+	AffectedEntities map[string]interface{} `json:"affected_entities" gorm:"type:jsonb;serializer:json"`
 	IsResolved       bool                   `json:"is_resolved" gorm:"default:false;index"`
 	ResolvedAt       *time.Time             `json:"resolved_at"`
 	CreatedAt        time.Time              `json:"created_at" gorm:"autoCreateTime"`
@@ -319,3 +327,101 @@ type ConflictLog struct {
 	// Relations
 	Timetable TimetableTemplate `json:"timetable,omitempty" gorm:"foreignKey:TimetableID"`
 }
+
+// GenerationRun records one run of the timetable generator: the settings it
+// used and how the search went, so runs can be compared
+type GenerationRun struct {
+	ID               uuid.UUID              `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	TimetableID      uuid.UUID              `json:"timetable_id" gorm:"not null;index"`
+	JobID            uuid.UUID              `json:"job_id" gorm:"not null"`
+	Status           string                 `json:"status" gorm:"default:'RUNNING';check:status IN ('RUNNING','COMPLETED','FAILED','CANCELLED')"`
+	Error            *string                `json:"error,omitempty"`
+	Algorithm        string                 `json:"algorithm" gorm:"not null"`
+	Config           map[string]interface{} `json:"config" gorm:"type:jsonb;serializer:json"`
+	Seed             int64                  `json:"seed"`
+	StartedAt        time.Time              `json:"started_at" gorm:"not null"`
+	FinishedAt       *time.Time             `json:"finished_at"`
+	DurationMs       int64                  `json:"duration_ms"`
+	Iterations       int64                  `json:"iterations"`
+	BestFitness      *float64               `json:"best_fitness"`
+	HardViolations   int                    `json:"hard_violations"`
+	SoftViolations   int                    `json:"soft_violations"`
+	ClassesScheduled int                    `json:"classes_scheduled"`
+	FitnessHistory   []FitnessPoint         `json:"fitness_history,omitempty" gorm:"type:jsonb;serializer:json"`
+	PenaltyBreakdown []ConstraintPenalty    `json:"penalty_breakdown,omitempty" gorm:"type:jsonb;serializer:json"`
+	Assignments      []RunAssignment        `json:"assignments,omitempty" gorm:"type:jsonb;serializer:json"`
+	CreatedBy        *uuid.UUID             `json:"created_by"`
+	CreatedAt        time.Time              `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// FitnessPoint is one sample of a generation run's fitness over time
+type FitnessPoint struct {
+	ElapsedMs      int64   `json:"elapsed_ms"`
+	Phase          string  `json:"phase"`
+	Iteration      int     `json:"iteration"`
+	CurrentFitness float64 `json:"current_fitness"`
+	BestFitness    float64 `json:"best_fitness"`
+}
+
+// ConstraintPenalty is one constraint's share of a generated timetable's
+// fitness
+type ConstraintPenalty struct {
+	Constraint string  `json:"constraint"`
+	Hard       bool    `json:"hard"`
+	Weight     float64 `json:"weight"`
+	Violated   bool    `json:"violated"`
+	Penalty    float64 `json:"penalty"`
+	Cost       float64 `json:"cost"` // taken off the fitness score
+}
+
+// RunAssignment is one meeting of the schedule a generation run found, kept
+// so the run can be promoted to the timetable later
+type RunAssignment struct {
+	Key        string    `json:"key"`
+	CourseID   uuid.UUID `json:"course_id"`
+	FacultyID  uuid.UUID `json:"faculty_id"`
+	RoomID     uuid.UUID `json:"room_id"`
+	TimeSlotID uuid.UUID `json:"time_slot_id"` // first slot of the meeting
+	DayOfWeek  int       `json:"day_of_week"`
+	StartTime  string    `json:"start_time"`
+	EndTime    string    `json:"end_time"`
+	EventIndex int       `json:"event_index"`
+	Length     int       `json:"length"`
+}
+
+// TimetableVersion is an immutable snapshot of a timetable's schedule, taken
+// each time it is published. A version is in force from its publication until
+// the next version of the timetable is published.
+type TimetableVersion struct {
+	ID           uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	TimetableID  uuid.UUID      `json:"timetable_id" gorm:"not null;uniqueIndex:idx_timetable_versions_number"`
+	Version      int            `json:"version" gorm:"not null;uniqueIndex:idx_timetable_versions_number"`
+	Name         string         `json:"name" gorm:"not null"`
+	PublishedAt  time.Time      `json:"published_at" gorm:"not null"`
+	PublishedBy  *uuid.UUID     `json:"published_by"`
+	SupersededAt *time.Time     `json:"superseded_at"`
+	ClassCount   int            `json:"class_count"`
+	Classes      []VersionClass `json:"classes,omitempty" gorm:"type:jsonb;serializer:json"`
+	CreatedAt    time.Time      `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// VersionClass is a scheduled class as it was when a version was published.
+// Course, faculty and room names are copied so the version still reads the
+// same after those records change.
+type VersionClass struct {
+	CourseID    uuid.UUID  `json:"course_id"`
+	CourseCode  string     `json:"course_code"`
+	CourseName  string     `json:"course_name"`
+	FacultyID   *uuid.UUID `json:"faculty_id"`
+	FacultyName string     `json:"faculty_name,omitempty"`
+	RoomID      *uuid.UUID `json:"room_id"`
+	RoomNumber  string     `json:"room_number,omitempty"`
+	TimeSlotID  uuid.UUID  `json:"time_slot_id"`
+	DayOfWeek   int        `json:"day_of_week"`
+	StartTime   string     `json:"start_time"`
+	EndTime     string     `json:"end_time"`
+	SemesterID  uuid.UUID  `json:"semester_id"`
+	IsLab       bool       `json:"is_lab"`
+	IsTutorial  bool       `json:"is_tutorial"`
+	BatchNumber *int       `json:"batch_number,omitempty"`
+}
@@ -0,0 +1,104 @@
+package optimization
+
+import (
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+// CourseCohort is a group of courses taken together by the same students,
+// such as the compulsory courses of a program in a given semester
+type CourseCohort struct {
+	GroupID   string
+	CourseIDs []uuid.UUID
+	Students  int
+}
+
+// CourseConflictGraph records, for every pair of courses, how many students
+// take both. Courses that share students must not meet at the same time.
+// A course is also linked to itself with its own student count, so two
+// overlapping meetings of one course are counted as a clash too.
+type CourseConflictGraph struct {
+	shared map[uuid.UUID]map[uuid.UUID]int
+	groups map[uuid.UUID]map[uuid.UUID][]string
+}
+
+// BuildCourseConflictGraph builds the graph from the semester's enrollments
+// and any curriculum cohorts. When both sources link the same pair of courses
+// the larger student count is kept so that students are not counted twice.
+func BuildCourseConflictGraph(enrollments []models.StudentEnrollment, cohorts []CourseCohort) *CourseConflictGraph {
+	graph := &CourseConflictGraph{
+		shared: make(map[uuid.UUID]map[uuid.UUID]int),
+		groups: make(map[uuid.UUID]map[uuid.UUID][]string),
+	}
+
+	// Group each student's courses
+	studentCourses := make(map[uuid.UUID][]uuid.UUID)
+	for _, enrollment := range enrollments {
+		if enrollment.Status == "DROPPED" {
+			continue
+		}
+		studentCourses[enrollment.StudentID] = append(studentCourses[enrollment.StudentID], enrollment.CourseID)
+	}
+
+	fromEnrollments := make(map[uuid.UUID]map[uuid.UUID]int)
+	for _, courses := range studentCourses {
+		for _, a := range courses {
+			for _, b := range courses {
+				if fromEnrollments[a] == nil {
+					fromEnrollments[a] = make(map[uuid.UUID]int)
+				}
+				fromEnrollments[a][b]++
+			}
+		}
+	}
+	for a, links := range fromEnrollments {
+		for b, students := range links {
+			graph.link(a, b, students)
+		}
+	}
+
+	for _, cohort := range cohorts {
+		for _, a := range cohort.CourseIDs {
+			for _, b := range cohort.CourseIDs {
+				graph.link(a, b, cohort.Students)
+				if cohort.GroupID != "" {
+					if graph.groups[a] == nil {
+						graph.groups[a] = make(map[uuid.UUID][]string)
+					}
+					graph.groups[a][b] = append(graph.groups[a][b], cohort.GroupID)
+				}
+			}
+		}
+	}
+
+	return graph
+}
+
+// link records that at least the given number of students take both courses
+func (g *CourseConflictGraph) link(a, b uuid.UUID, students int) {
+	if students <= 0 {
+		return
+	}
+	if g.shared[a] == nil {
+		g.shared[a] = make(map[uuid.UUID]int)
+	}
+	if students > g.shared[a][b] {
+		g.shared[a][b] = students
+	}
+}
+
+// Shared returns the number of students taking both courses
+func (g *CourseConflictGraph) Shared(a, b uuid.UUID) int {
+	if g == nil {
+		return 0
+	}
+	return g.shared[a][b]
+}
+
+// Groups returns the IDs of the cohorts that take both courses
+func (g *CourseConflictGraph) Groups(a, b uuid.UUID) []string {
+	if g == nil {
+		return nil
+	}
+	return g.groups[a][b]
+}
@@ -0,0 +1,109 @@
+package optimization
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestBuildCourseConflictGraph(t *testing.T) {
+	a, b, c, d, e := testID("A"), testID("B"), testID("C"), testID("D"), testID("E")
+
+	enroll := func(student string, course uuid.UUID, status string) models.StudentEnrollment {
+		return models.StudentEnrollment{StudentID: testID(student), CourseID: course, Status: status}
+	}
+	enrollments := []models.StudentEnrollment{
+		enroll("s1", a, "ENROLLED"), enroll("s1", b, "ENROLLED"),
+		enroll("s2", a, "ENROLLED"), enroll("s2", b, "ENROLLED"),
+		enroll("s3", a, "ENROLLED"), enroll("s3", b, "ENROLLED"), enroll("s3", c, "ENROLLED"),
+		enroll("s4", a, "DROPPED"), enroll("s4", c, "ENROLLED"),
+	}
+	cohorts := []CourseCohort{
+		{GroupID: "BSC-1", CourseIDs: []uuid.UUID{a, d}, Students: 40},
+		// Fewer than enrolled in both, so the enrollments count
+		{GroupID: "BSC-2", CourseIDs: []uuid.UUID{a, b}, Students: 2},
+		// An empty cohort links nothing
+		{CourseIDs: []uuid.UUID{d, e}},
+	}
+
+	graph := BuildCourseConflictGraph(enrollments, cohorts)
+
+	names := map[uuid.UUID]string{a: "A", b: "B", c: "C", d: "D", e: "E"}
+	tests := []struct {
+		a, b   uuid.UUID
+		shared int
+		groups []string
+	}{
+		{a, b, 3, []string{"BSC-2"}},
+		{b, a, 3, []string{"BSC-2"}},
+		{a, a, 40, []string{"BSC-1", "BSC-2"}},
+		{b, b, 3, []string{"BSC-2"}},
+		{a, c, 1, nil},
+		{c, c, 2, nil},
+		{a, d, 40, []string{"BSC-1"}},
+		{d, a, 40, []string{"BSC-1"}},
+		{b, d, 0, nil},
+		{d, e, 0, nil},
+		{a, e, 0, nil},
+	}
+	for _, tt := range tests {
+		if got := graph.Shared(tt.a, tt.b); got != tt.shared {
+			t.Errorf("%s and %s share %d students, want %d", names[tt.a], names[tt.b], got, tt.shared)
+		}
+		if got := graph.Groups(tt.a, tt.b); !slices.Equal(got, tt.groups) {
+			t.Errorf("%s and %s are taken by groups %v, want %v", names[tt.a], names[tt.b], got, tt.groups)
+		}
+	}
+
+	var none *CourseConflictGraph
+	if none.Shared(a, b) != 0 || none.Groups(a, b) != nil {
+		t.Error("a nil graph links courses")
+	}
+}
+
+func TestStudentGroupClashScalesWithStudents(t *testing.T) {
+	a, b, c, d := testID("A"), testID("B"), testID("C"), testID("D")
+	graph := BuildCourseConflictGraph(nil, []CourseCohort{
+		{GroupID: "BSC-1", CourseIDs: []uuid.UUID{a, b}, Students: 30},
+		{GroupID: "BSC-2", CourseIDs: []uuid.UUID{a, c}, Students: 5},
+		{GroupID: "BSC-3", CourseIDs: []uuid.UUID{a, d}, Students: 50},
+	})
+	constraint := &StudentGroupClash{Conflicts: graph}
+
+	meeting := func(course uuid.UUID, day int, start, end string) *ClassAssignment {
+		return &ClassAssignment{CourseID: course, FacultyID: uuid.New(), RoomID: uuid.New(), DayOfWeek: day, StartTime: start, EndTime: end}
+	}
+	solution := &Solution{Schedule: map[string]*ClassAssignment{
+		"a": meeting(a, 1, "09:00", "10:00"),
+		"b": meeting(b, 1, "09:30", "10:30"),
+		"c": meeting(c, 1, "09:00", "10:00"),
+		// Shares the most students with A, but meets on another day
+		"d": meeting(d, 2, "09:00", "10:00"),
+	}}
+
+	violations := constraint.Violations(solution)
+	got := map[string]int{}
+	for _, violation := range violations {
+		got[violation.Keys[0]+"-"+violation.Keys[1]] = violation.StudentsAffected
+		if violation.Penalty != float64(violation.StudentsAffected) {
+			t.Errorf("clash of %v affects %d students but costs %v", violation.Keys, violation.StudentsAffected, violation.Penalty)
+		}
+	}
+	want := map[string]int{"a-b": 30, "a-c": 5}
+	if len(got) != len(want) || got["a-b"] != 30 || got["a-c"] != 5 {
+		t.Fatalf("clashes are %v, want %v", got, want)
+	}
+
+	violated, penalty := constraint.Evaluate(solution)
+	if !violated || penalty != 35 {
+		t.Fatalf("Evaluate = %v, %v; want true, 35", violated, penalty)
+	}
+
+	// Moving B off A's hour leaves only the smaller clash
+	solution.Schedule["b"] = meeting(b, 1, "10:00", "11:00")
+	if violated, penalty := constraint.Evaluate(solution); !violated || penalty != 5 {
+		t.Fatalf("after the move Evaluate = %v, %v; want true, 5", violated, penalty)
+	}
+}
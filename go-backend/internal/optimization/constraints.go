@@ -1,29 +1,113 @@
 package optimization
 
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Clash is a pair of assignments that need the same faculty member or room
+// at overlapping times
+type Clash struct {
+	ResourceID     uuid.UUID `json:"resource_id"`
+	DayOfWeek      int       `json:"day_of_week"`
+	FirstKey       string    `json:"first_key"`
+	SecondKey      string    `json:"second_key"`
+	OverlapMinutes int       `json:"overlap_minutes"`
+}
+
+// findResourceClashes groups assignments by the resource they use and day,
+// then reports every pair whose parsed times overlap
+func findResourceClashes(solution *Solution, resourceOf func(*ClassAssignment) uuid.UUID) []Clash {
+	type resourceDay struct {
+		resource uuid.UUID
+		day      int
+	}
+
+	groups := make(map[resourceDay][]string)
+	order := []resourceDay{}
+	for _, key := range sortedKeys(solution) {
+		assignment := solution.Schedule[key]
+		group := resourceDay{resourceOf(assignment), assignment.DayOfWeek}
+		if _, seen := groups[group]; !seen {
+			order = append(order, group)
+		}
+		groups[group] = append(groups[group], key)
+	}
+
+	clashes := []Clash{}
+	for _, group := range order {
+		keys := groups[group]
+		for i := 0; i < len(keys); i++ {
+			for j := i + 1; j < len(keys); j++ {
+				overlap := solution.Schedule[keys[i]].overlapMinutes(solution.Schedule[keys[j]])
+				if overlap == 0 {
+					continue
+				}
+				clashes = append(clashes, Clash{
+					ResourceID:     group.resource,
+					DayOfWeek:      group.day,
+					FirstKey:       keys[i],
+					SecondKey:      keys[j],
+					OverlapMinutes: overlap,
+				})
+			}
+		}
+	}
+
+	return clashes
+}
+
+// clashViolations turns resource clashes into critical violations
+func clashViolations(solution *Solution, clashes []Clash, conflictType, description string) []Violation {
+	violations := make([]Violation, 0, len(clashes))
+	for _, clash := range clashes {
+		violations = append(violations, newViolation(solution, conflictType, SeverityCritical, description, 1,
+			clash.FirstKey, clash.SecondKey))
+	}
+	return violations
+}
+
+// sumPenalties folds a violation list into Evaluate's return values
+func sumPenalties(violations []Violation) (bool, float64) {
+	penalty := 0.0
+	for _, violation := range violations {
+		penalty += violation.Penalty
+	}
+	return len(violations) > 0, penalty
+}
+
 // NoFacultyDoubleBooking ensures no faculty is assigned to two classes at the same time
 type NoFacultyDoubleBooking struct{}
 
 func (c *NoFacultyDoubleBooking) IsHard() bool { return true }
 
 func (c *NoFacultyDoubleBooking) Evaluate(solution *Solution) (bool, float64) {
-	violations := 0
-	facultySchedule := make(map[string][]string) // faculty_id -> [time_slots]
+	clashes := c.Clashes(solution)
+	return len(clashes) > 0, float64(len(clashes))
+}
 
-	for _, assignment := range solution.Schedule {
-		key := assignment.FacultyID.String()
-		slot := assignment.DayOfWeek
-		timeKey := string(rune(slot)) + ":" + assignment.StartTime + "-" + assignment.EndTime
-
-		if existing, found := facultySchedule[key]; found {
-			for range existing {
-				// Check for overlap
-				violations++
-			}
-		}
-		facultySchedule[key] = append(facultySchedule[key], timeKey)
-	}
+// Clashes lists every pair of classes taught by one faculty member at overlapping times
+func (c *NoFacultyDoubleBooking) Clashes(solution *Solution) []Clash {
+	return findResourceClashes(solution, func(a *ClassAssignment) uuid.UUID { return a.FacultyID })
+}
 
-	return violations > 0, float64(violations)
+// Violations reports each faculty clash with the two classes involved
+func (c *NoFacultyDoubleBooking) Violations(solution *Solution) []Violation {
+	return clashViolations(solution, c.Clashes(solution), ConflictFacultyDoubleBooking,
+		"Faculty member is assigned to two classes at the same time")
+}
+
+func (c *NoFacultyDoubleBooking) delta(solution *Solution, change *moveChange) float64 {
+	return pairDelta(solution, change,
+		func(a *ClassAssignment) []string { return solution.index.byFaculty[a.FacultyID] },
+		func(a, b *ClassAssignment) float64 {
+			if a.FacultyID == b.FacultyID && a.overlaps(b) {
+				return 1
+			}
+			return 0
+		})
 }
 
 func (c *NoFacultyDoubleBooking) GetDescription() string {
@@ -36,23 +120,30 @@ type NoRoomDoubleBooking struct{}
 func (c *NoRoomDoubleBooking) IsHard() bool { return true }
 
 func (c *NoRoomDoubleBooking) Evaluate(solution *Solution) (bool, float64) {
-	violations := 0
-	roomSchedule := make(map[string][]string) // room_id -> [time_slots]
+	clashes := c.Clashes(solution)
+	return len(clashes) > 0, float64(len(clashes))
+}
 
-	for _, assignment := range solution.Schedule {
-		key := assignment.RoomID.String()
-		slot := assignment.DayOfWeek
-		timeKey := string(rune(slot)) + ":" + assignment.StartTime + "-" + assignment.EndTime
+// Clashes lists every pair of classes held in one room at overlapping times
+func (c *NoRoomDoubleBooking) Clashes(solution *Solution) []Clash {
+	return findResourceClashes(solution, func(a *ClassAssignment) uuid.UUID { return a.RoomID })
+}
 
-		if existing, found := roomSchedule[key]; found {
-			for range existing {
-				violations++
-			}
-		}
-		roomSchedule[key] = append(roomSchedule[key], timeKey)
-	}
+// Violations reports each room clash with the two classes involved
+func (c *NoRoomDoubleBooking) Violations(solution *Solution) []Violation {
+	return clashViolations(solution, c.Clashes(solution), ConflictRoomDoubleBooking,
+		"Room is booked for two classes at the same time")
+}
 
-	return violations > 0, float64(violations)
+func (c *NoRoomDoubleBooking) delta(solution *Solution, change *moveChange) float64 {
+	return pairDelta(solution, change,
+		func(a *ClassAssignment) []string { return solution.index.byRoom[a.RoomID] },
+		func(a, b *ClassAssignment) float64 {
+			if a.RoomID == b.RoomID && a.overlaps(b) {
+				return 1
+			}
+			return 0
+		})
 }
 
 func (c *NoRoomDoubleBooking) GetDescription() string {
@@ -67,24 +158,71 @@ type FacultyWorkloadLimit struct {
 func (c *FacultyWorkloadLimit) IsHard() bool { return true }
 
 func (c *FacultyWorkloadLimit) Evaluate(solution *Solution) (bool, float64) {
-	violations := 0
-	facultyHours := make(map[string]int)
+	return sumPenalties(c.Violations(solution))
+}
 
-	for _, assignment := range solution.Schedule {
-		facultyID := assignment.FacultyID.String()
-		// Calculate hours (simplified - should parse time strings)
-		facultyHours[facultyID] += 1
+// Violations reports each overloaded faculty member with all of their classes
+func (c *FacultyWorkloadLimit) Violations(solution *Solution) []Violation {
+	facultyHours := make(map[uuid.UUID]int)
+	facultyKeys := make(map[uuid.UUID][]string)
+	order := []uuid.UUID{}
+
+	for _, key := range sortedKeys(solution) {
+		assignment := solution.Schedule[key]
+		if _, seen := facultyKeys[assignment.FacultyID]; !seen {
+			order = append(order, assignment.FacultyID)
+		}
+		// Calculate hours (one hour per occupied slot)
+		facultyHours[assignment.FacultyID] += assignment.slotCount()
+		facultyKeys[assignment.FacultyID] = append(facultyKeys[assignment.FacultyID], key)
 	}
 
-	for facultyID, hours := range facultyHours {
-		if maxHours, exists := c.MaxHours[facultyID]; exists {
-			if hours > maxHours {
-				violations += hours - maxHours
-			}
+	violations := []Violation{}
+	for _, facultyID := range order {
+		maxHours, exists := c.MaxHours[facultyID.String()]
+		hours := facultyHours[facultyID]
+		if !exists || hours <= maxHours {
+			continue
+		}
+		violations = append(violations, newViolation(solution, ConflictFacultyOverload, SeverityHigh,
+			fmt.Sprintf("Faculty member is scheduled for %d hours, above the limit of %d", hours, maxHours),
+			float64(hours-maxHours), facultyKeys[facultyID]...))
+	}
+
+	return violations
+}
+
+func (c *FacultyWorkloadLimit) delta(solution *Solution, change *moveChange) float64 {
+	// Hours gained or lost by each faculty member the move touches
+	shift := make(map[uuid.UUID]int)
+	for _, assignment := range change.removed {
+		shift[assignment.FacultyID] -= assignment.slotCount()
+	}
+	for _, assignment := range change.added {
+		shift[assignment.FacultyID] += assignment.slotCount()
+	}
+
+	excess := func(hours, maxHours int) float64 {
+		if hours <= maxHours {
+			return 0
+		}
+		return float64(hours - maxHours)
+	}
+
+	delta := 0.0
+	for facultyID, hours := range shift {
+		maxHours, exists := c.MaxHours[facultyID.String()]
+		if !exists || hours == 0 {
+			continue
+		}
+		before := 0
+		for _, key := range solution.index.byFaculty[facultyID] {
+			before += solution.Schedule[key].slotCount()
 		}
+		delta += excess(before+hours, maxHours) - excess(before, maxHours)
 	}
 
-	return violations > 0, float64(violations)
+	return delta
 }
 
 func (c *FacultyWorkloadLimit) GetDescription() string {
@@ -100,21 +238,41 @@ type RoomCapacityConstraint struct {
 func (c *RoomCapacityConstraint) IsHard() bool { return true }
 
 func (c *RoomCapacityConstraint) Evaluate(solution *Solution) (bool, float64) {
-	violations := 0
+	return sumPenalties(c.Violations(solution))
+}
 
-	for _, assignment := range solution.Schedule {
-		roomID := assignment.RoomID.String()
-		courseID := assignment.CourseID.String()
+// Violations reports each class held in a room too small for its students
+func (c *RoomCapacityConstraint) Violations(solution *Solution) []Violation {
+	violations := []Violation{}
+
+	for _, key := range sortedKeys(solution) {
+		assignment := solution.Schedule[key]
+		if excess := c.excess(assignment); excess > 0 {
+			capacity := c.RoomCapacities[assignment.RoomID.String()]
+			violation := newViolation(solution, ConflictRoomCapacity, SeverityHigh,
+				fmt.Sprintf("Room seats %d but %d students are enrolled", capacity, capacity+excess),
+				float64(excess), key)
+			violation.StudentsAffected = excess
+			violations = append(violations, violation)
+		}
+	}
 
-		capacity, roomExists := c.RoomCapacities[roomID]
-		enrollment, courseExists := c.CourseEnrollments[courseID]
+	return violations
+}
 
-		if roomExists && courseExists && enrollment > capacity {
-			violations += enrollment - capacity
-		}
+// excess returns how many enrolled students the assignment's room cannot seat
+func (c *RoomCapacityConstraint) excess(assignment *ClassAssignment) int {
+	capacity, roomExists := c.RoomCapacities[assignment.RoomID.String()]
+	enrollment, courseExists := c.CourseEnrollments[assignment.CourseID.String()]
+
+	if roomExists && courseExists && enrollment > capacity {
+		return enrollment - capacity
 	}
+	return 0
+}
 
-	return violations > 0, float64(violations)
+func (c *RoomCapacityConstraint) delta(solution *Solution, change *moveChange) float64 {
+	return assignmentDelta(change, func(a *ClassAssignment) float64 { return float64(c.excess(a)) })
 }
 
 func (c *RoomCapacityConstraint) GetDescription() string {
@@ -130,7 +288,12 @@ type LabRoomRequirement struct {
 func (c *LabRoomRequirement) IsHard() bool { return true }
 
 func (c *LabRoomRequirement) Evaluate(solution *Solution) (bool, float64) {
-	violations := 0
+	return sumPenalties(c.Violations(solution))
+}
+
+// Violations reports each lab class placed outside a lab room
+func (c *LabRoomRequirement) Violations(solution *Solution) []Violation {
+	violations := []Violation{}
 
 	labCourseMap := make(map[string]bool)
 	for _, courseID := range c.LabCourses {
@@ -142,16 +305,37 @@ func (c *LabRoomRequirement) Evaluate(solution *Solution) (bool, float64) {
 		labRoomMap[roomID] = true
 	}
 
-	for _, assignment := range solution.Schedule {
+	for _, key := range sortedKeys(solution) {
+		assignment := solution.Schedule[key]
 		courseID := assignment.CourseID.String()
 		roomID := assignment.RoomID.String()
 
 		if labCourseMap[courseID] && !labRoomMap[roomID] {
-			violations++
+			violations = append(violations, newViolation(solution, ConflictLabRoom, SeverityHigh,
+				"Lab class is not scheduled in a lab room", 1, key))
 		}
 	}
 
-	return violations > 0, float64(violations)
+	return violations
+}
+
+func (c *LabRoomRequirement) delta(solution *Solution, change *moveChange) float64 {
+	labCourseMap := make(map[string]bool)
+	for _, courseID := range c.LabCourses {
+		labCourseMap[courseID] = true
+	}
+
+	labRoomMap := make(map[string]bool)
+	for _, roomID := range c.LabRooms {
+		labRoomMap[roomID] = true
+	}
+
+	return assignmentDelta(change, func(a *ClassAssignment) float64 {
+		if labCourseMap[a.CourseID.String()] && !labRoomMap[a.RoomID.String()] {
+			return 1
+		}
+		return 0
+	})
 }
 
 func (c *LabRoomRequirement) GetDescription() string {
@@ -171,42 +355,138 @@ type TimeRange struct {
 func (c *FacultyAvailability) IsHard() bool { return true }
 
 func (c *FacultyAvailability) Evaluate(solution *Solution) (bool, float64) {
-	violations := 0
+	return sumPenalties(c.Violations(solution))
+}
 
-	for _, assignment := range solution.Schedule {
-		facultyID := assignment.FacultyID.String()
-		day := assignment.DayOfWeek
-
-		if facultyAvail, exists := c.Availability[facultyID]; exists {
-			if dayAvail, dayExists := facultyAvail[day]; dayExists {
-				available := false
-				for _, timeRange := range dayAvail {
-					if assignment.StartTime >= timeRange.Start && assignment.EndTime <= timeRange.End {
-						available = true
-						break
-					}
+// Violations reports each class scheduled outside its faculty member's availability
+func (c *FacultyAvailability) Violations(solution *Solution) []Violation {
+	violations := []Violation{}
+
+	for _, key := range sortedKeys(solution) {
+		if !c.available(solution.Schedule[key]) {
+			violations = append(violations, newViolation(solution, ConflictFacultyUnavailable, SeverityHigh,
+				"Class is scheduled outside the faculty member's availability", 1, key))
+		}
+	}
+
+	return violations
+}
+
+// available reports whether the assignment falls inside one of its faculty
+// member's available time ranges. Times are compared as clock times, so
+// "09:00" and "09:00:00" are the same. Faculty without recorded availability
+// are always available; ranges that cannot be parsed are ignored.
+func (c *FacultyAvailability) available(assignment *ClassAssignment) bool {
+	facultyAvail, exists := c.Availability[assignment.FacultyID.String()]
+	if !exists {
+		return true
+	}
+
+	start, end, ok := assignment.interval()
+	if !ok {
+		return false
+	}
+
+	for _, timeRange := range facultyAvail[assignment.DayOfWeek] {
+		from, errFrom := ParseClock(timeRange.Start)
+		to, errTo := ParseClock(timeRange.End)
+		if errFrom != nil || errTo != nil {
+			continue
+		}
+		if start >= from && end <= to {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *FacultyAvailability) delta(solution *Solution, change *moveChange) float64 {
+	return assignmentDelta(change, func(a *ClassAssignment) float64 {
+		if c.available(a) {
+			return 0
+		}
+		return 1
+	})
+}
+
+func (c *FacultyAvailability) GetDescription() string {
+	return "Faculty must be scheduled only during their available time slots"
+}
+
+// StudentGroupClash ensures courses that share students do not overlap
+type StudentGroupClash struct {
+	Conflicts *CourseConflictGraph
+}
+
+func (c *StudentGroupClash) IsHard() bool { return true }
+
+func (c *StudentGroupClash) Evaluate(solution *Solution) (bool, float64) {
+	return sumPenalties(c.Violations(solution))
+}
+
+// Violations reports each pair of overlapping classes that share students,
+// weighted by the number of students affected
+func (c *StudentGroupClash) Violations(solution *Solution) []Violation {
+	violations := []Violation{}
+
+	// Group by day so only meetings on the same day are compared
+	dayKeys := make(map[int][]string)
+	days := []int{}
+	for _, key := range sortedKeys(solution) {
+		day := solution.Schedule[key].DayOfWeek
+		if _, seen := dayKeys[day]; !seen {
+			days = append(days, day)
+		}
+		dayKeys[day] = append(dayKeys[day], key)
+	}
+
+	for _, day := range days {
+		keys := dayKeys[day]
+		for i := 0; i < len(keys); i++ {
+			for j := i + 1; j < len(keys); j++ {
+				a, b := solution.Schedule[keys[i]], solution.Schedule[keys[j]]
+				if !a.overlaps(b) {
+					continue
 				}
-				if !available {
-					violations++
+				students := c.Conflicts.Shared(a.CourseID, b.CourseID)
+				if students == 0 {
+					continue
 				}
-			} else {
-				violations++
+				violation := newViolation(solution, ConflictStudentClash, SeverityCritical,
+					fmt.Sprintf("%d students are scheduled for two classes at the same time", students),
+					float64(students), keys[i], keys[j])
+				violation.StudentGroupIDs = c.Conflicts.Groups(a.CourseID, b.CourseID)
+				violation.StudentsAffected = students
+				violations = append(violations, violation)
 			}
 		}
 	}
 
-	return violations > 0, float64(violations)
+	return violations
 }
 
-func (c *FacultyAvailability) GetDescription() string {
-	return "Faculty must be scheduled only during their available time slots"
+func (c *StudentGroupClash) delta(solution *Solution, change *moveChange) float64 {
+	return pairDelta(solution, change,
+		func(a *ClassAssignment) []string { return solution.index.byDay[a.DayOfWeek] },
+		func(a, b *ClassAssignment) float64 {
+			if !a.overlaps(b) {
+				return 0
+			}
+			return float64(c.Conflicts.Shared(a.CourseID, b.CourseID))
+		})
+}
+
+func (c *StudentGroupClash) GetDescription() string {
+	return "Courses taken by the same students cannot be scheduled at the same time"
 }
 
 // SOFT CONSTRAINTS
 
 // PreferMorningForTheory prefers scheduling theory classes in the morning
 type PreferMorningForTheory struct {
-	TheoryCourses []string
+	TheoryCourses  []string
+	AfternoonStart string // classes starting later are penalised; "12:00" if empty
+	EveningStart   string // classes starting later are penalised more; "15:00" if empty
 }
 
 func (c *PreferMorningForTheory) IsHard() bool { return false }
@@ -220,20 +500,39 @@ func (c *PreferMorningForTheory) Evaluate(solution *Solution) (bool, float64) {
 	}
 
 	for _, assignment := range solution.Schedule {
-		courseID := assignment.CourseID.String()
+		penalty += c.penalty(theoryCourseMap, assignment)
+	}
 
-		if theoryCourseMap[courseID] {
-			// Parse start time (simplified)
-			if assignment.StartTime > "12:00" {
-				penalty += 5.0 // Afternoon classes get penalty
-			}
-			if assignment.StartTime > "15:00" {
-				penalty += 10.0 // Evening classes get higher penalty
-			}
+	return penalty > 0, penalty
+}
+
+func (c *PreferMorningForTheory) penalty(theoryCourseMap map[string]bool, assignment *ClassAssignment) float64 {
+	penalty := 0.0
+	if theoryCourseMap[assignment.CourseID.String()] {
+		start, _, ok := assignment.interval()
+		if !ok {
+			return 0
+		}
+		afternoon := clockOrDefault(c.AfternoonStart, 12*60)
+		evening := clockOrDefault(c.EveningStart, 15*60)
+
+		if start > afternoon {
+			penalty += 5.0 // Afternoon classes get penalty
+		}
+		if start > evening {
+			penalty += 10.0 // Evening classes get higher penalty
 		}
 	}
+	return penalty
+}
 
-	return penalty > 0, penalty
+func (c *PreferMorningForTheory) delta(solution *Solution, change *moveChange) float64 {
+	theoryCourseMap := make(map[string]bool)
+	for _, courseID := range c.TheoryCourses {
+		theoryCourseMap[courseID] = true
+	}
+
+	return assignmentDelta(change, func(a *ClassAssignment) float64 { return c.penalty(theoryCourseMap, a) })
 }
 
 func (c *PreferMorningForTheory) GetDescription() string {
@@ -251,20 +550,25 @@ func (c *FacultyPreference) Evaluate(solution *Solution) (bool, float64) {
 	penalty := 0.0
 
 	for _, assignment := range solution.Schedule {
-		facultyID := assignment.FacultyID.String()
-		courseID := assignment.CourseID.String()
+		penalty += c.penalty(assignment)
+	}
 
-		if facultyPrefs, exists := c.Preferences[facultyID]; exists {
-			if pref, hasPref := facultyPrefs[courseID]; hasPref {
-				// Lower preference = higher penalty
-				penalty += float64(5 - pref)
-			} else {
-				penalty += 5.0 // No preference listed = max penalty
-			}
+	return penalty > 0, penalty
+}
+
+func (c *FacultyPreference) penalty(assignment *ClassAssignment) float64 {
+	if facultyPrefs, exists := c.Preferences[assignment.FacultyID.String()]; exists {
+		if pref, hasPref := facultyPrefs[assignment.CourseID.String()]; hasPref {
+			// Lower preference = higher penalty
+			return float64(5 - pref)
 		}
+		return 5.0 // No preference listed = max penalty
 	}
+	return 0
+}
 
-	return penalty > 0, penalty
+func (c *FacultyPreference) delta(solution *Solution, change *moveChange) float64 {
+	return assignmentDelta(change, c.penalty)
 }
 
 func (c *FacultyPreference) GetDescription() string {
@@ -286,14 +590,18 @@ func (c *AvoidBackToBackLabs) Evaluate(solution *Solution) (bool, float64) {
 		labCourseMap[courseID] = true
 	}
 
-	// Group by day
+	// Group by day, in time order
 	daySchedule := make(map[int][]*ClassAssignment)
-	for _, assignment := range solution.Schedule {
+	for _, key := range sortedKeys(solution) {
+		assignment := solution.Schedule[key]
 		daySchedule[assignment.DayOfWeek] = append(daySchedule[assignment.DayOfWeek], assignment)
 	}
 
 	// Check for back-to-back labs
 	for _, assignments := range daySchedule {
+		sort.SliceStable(assignments, func(i, j int) bool {
+			return assignments[i].StartTime < assignments[j].StartTime
+		})
 		for i := 0; i < len(assignments)-1; i++ {
 			curr := assignments[i]
 			next := assignments[i+1]
@@ -325,27 +633,58 @@ func (c *BalancedDailyDistribution) Evaluate(solution *Solution) (bool, float64)
 		dayCount[assignment.DayOfWeek]++
 	}
 
+	penalty := dayVariance(dayCount)
+
+	return penalty > 0, penalty
+}
+
+// dayVariance returns the variance of the class counts of the days in use
+func dayVariance(dayCount map[int]int) float64 {
+	// Sum in day order so the result is the same on every run
+	days := make([]int, 0, len(dayCount))
+	for day, count := range dayCount {
+		if count > 0 {
+			days = append(days, day)
+		}
+	}
+	sort.Ints(days)
+
 	// Calculate standard deviation
-	if len(dayCount) == 0 {
-		return false, 0
+	if len(days) == 0 {
+		return 0
 	}
 
 	sum := 0
-	for _, count := range dayCount {
-		sum += count
+	for _, day := range days {
+		sum += dayCount[day]
 	}
-	mean := float64(sum) / float64(len(dayCount))
+	mean := float64(sum) / float64(len(days))
 
 	variance := 0.0
-	for _, count := range dayCount {
-		diff := float64(count) - mean
+	for _, day := range days {
+		diff := float64(dayCount[day]) - mean
 		variance += diff * diff
 	}
-	variance /= float64(len(dayCount))
+	variance /= float64(len(days))
 
-	penalty := variance
+	return variance
+}
 
-	return penalty > 0, penalty
+func (c *BalancedDailyDistribution) delta(solution *Solution, change *moveChange) float64 {
+	before := make(map[int]int, len(solution.index.byDay))
+	after := make(map[int]int, len(solution.index.byDay))
+	for day, keys := range solution.index.byDay {
+		before[day] = len(keys)
+		after[day] = len(keys)
+	}
+	for _, assignment := range change.removed {
+		after[assignment.DayOfWeek]--
+	}
+	for _, assignment := range change.added {
+		after[assignment.DayOfWeek]++
+	}
+
+	return dayVariance(after) - dayVariance(before)
 }
 
 func (c *BalancedDailyDistribution) GetDescription() string {
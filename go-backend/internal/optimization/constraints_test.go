@@ -0,0 +1,61 @@
+package optimization
+
+import "testing"
+
+func TestFacultyAvailabilityComparesClockTimes(t *testing.T) {
+	facultyID := testID("faculty")
+	constraint := &FacultyAvailability{
+		Availability: map[string]map[int][]TimeRange{
+			facultyID.String(): {1: {{Start: "09:00:00", End: "12:00:00"}}},
+		},
+	}
+
+	tests := []struct {
+		day        int
+		start, end string
+		want       bool
+	}{
+		{1, "09:00", "10:00", true},
+		{1, "11:00:00", "12:00:00", true},
+		{1, "9:00", "10:00", true},
+		{1, "08:00", "09:00", false},
+		{1, "11:30", "12:30", false},
+		{2, "09:00", "10:00", false},
+	}
+	for _, tt := range tests {
+		assignment := &ClassAssignment{FacultyID: facultyID, DayOfWeek: tt.day, StartTime: tt.start, EndTime: tt.end}
+		if got := constraint.available(assignment); got != tt.want {
+			t.Errorf("available(day %d, %s-%s) = %v, want %v", tt.day, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestPreferMorningForTheoryComparesClockTimes(t *testing.T) {
+	courseID := testID("theory")
+
+	tests := []struct {
+		afternoon, evening string
+		start              string
+		want               float64
+	}{
+		{"", "", "09:00:00", 0},
+		{"", "", "12:00:00", 0},
+		{"", "", "12:00", 0},
+		{"", "", "13:00:00", 5},
+		{"", "", "16:00:00", 15},
+		{"9:00", "", "10:00:00", 5},
+		{"9:00", "", "9:00", 0},
+		{"13:00", "14:00", "13:30:00", 5},
+	}
+	for _, tt := range tests {
+		constraint := &PreferMorningForTheory{
+			TheoryCourses:  []string{courseID.String()},
+			AfternoonStart: tt.afternoon,
+			EveningStart:   tt.evening,
+		}
+		assignment := &ClassAssignment{CourseID: courseID, StartTime: tt.start, EndTime: "23:00"}
+		if got := constraint.penalty(map[string]bool{courseID.String(): true}, assignment); got != tt.want {
+			t.Errorf("penalty(%s) with afternoon %q, evening %q = %v, want %v", tt.start, tt.afternoon, tt.evening, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package optimization
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// penaltyEpsilon absorbs rounding left over from adding up fractional deltas
+const penaltyEpsilon = 1e-9
+
+// constraintResult is the last evaluation of one constraint on a solution
+type constraintResult struct {
+	violated bool
+	penalty  float64
+}
+
+// occupancy indexes a solution's schedule keys by faculty member, room and day
+// so that a move only has to be compared with the assignments it can interact
+// with. It also keeps every key in sorted order, so moves can pick one without
+// sorting the schedule. Copies of a solution share the slices; a slice is
+// replaced, never modified, when its entry changes.
+type occupancy struct {
+	keys      []string
+	byFaculty map[uuid.UUID][]string
+	byRoom    map[uuid.UUID][]string
+	byDay     map[int][]string
+}
+
+// buildOccupancy indexes every assignment of the solution
+func buildOccupancy(solution *Solution) *occupancy {
+	index := &occupancy{
+		keys:      make([]string, 0, len(solution.Schedule)),
+		byFaculty: make(map[uuid.UUID][]string),
+		byRoom:    make(map[uuid.UUID][]string),
+		byDay:     make(map[int][]string),
+	}
+
+	for key := range solution.Schedule {
+		index.keys = append(index.keys, key)
+	}
+	sort.Strings(index.keys)
+
+	for _, key := range index.keys {
+		assignment := solution.Schedule[key]
+		index.byFaculty[assignment.FacultyID] = append(index.byFaculty[assignment.FacultyID], key)
+		index.byRoom[assignment.RoomID] = append(index.byRoom[assignment.RoomID], key)
+		index.byDay[assignment.DayOfWeek] = append(index.byDay[assignment.DayOfWeek], key)
+	}
+
+	return index
+}
+
+// clone copies the index maps; the key slices stay shared
+func (o *occupancy) clone() *occupancy {
+	index := &occupancy{
+		keys:      o.keys,
+		byFaculty: make(map[uuid.UUID][]string, len(o.byFaculty)),
+		byRoom:    make(map[uuid.UUID][]string, len(o.byRoom)),
+		byDay:     make(map[int][]string, len(o.byDay)),
+	}
+	for id, keys := range o.byFaculty {
+		index.byFaculty[id] = keys
+	}
+	for id, keys := range o.byRoom {
+		index.byRoom[id] = keys
+	}
+	for day, keys := range o.byDay {
+		index.byDay[day] = keys
+	}
+	return index
+}
+
+func (o *occupancy) add(key string, assignment *ClassAssignment) {
+	o.byFaculty[assignment.FacultyID] = withKey(o.byFaculty[assignment.FacultyID], key)
+	o.byRoom[assignment.RoomID] = withKey(o.byRoom[assignment.RoomID], key)
+	o.byDay[assignment.DayOfWeek] = withKey(o.byDay[assignment.DayOfWeek], key)
+}
+
+func (o *occupancy) remove(key string, assignment *ClassAssignment) {
+	o.byFaculty[assignment.FacultyID] = withoutKey(o.byFaculty[assignment.FacultyID], key)
+	o.byRoom[assignment.RoomID] = withoutKey(o.byRoom[assignment.RoomID], key)
+	o.byDay[assignment.DayOfWeek] = withoutKey(o.byDay[assignment.DayOfWeek], key)
+}
+
+// replaceKeys takes the removed keys out of the sorted key list and merges the
+// sorted added keys in, none of which may already be in the list
+func (o *occupancy) replaceKeys(removed map[string]bool, added []string) {
+	keys := make([]string, 0, len(o.keys)+len(added))
+	for _, key := range o.keys {
+		if removed[key] {
+			continue
+		}
+		for len(added) > 0 && added[0] < key {
+			keys = append(keys, added[0])
+			added = added[1:]
+		}
+		keys = append(keys, key)
+	}
+	o.keys = append(keys, added...)
+}
+
+// withKey returns a new slice holding keys and key
+func withKey(keys []string, key string) []string {
+	result := make([]string, len(keys), len(keys)+1)
+	copy(result, keys)
+	return append(result, key)
+}
+
+// withoutKey returns a new slice holding keys except key
+func withoutKey(keys []string, key string) []string {
+	result := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k != key {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+// moveChange is a move resolved against the solution it is about to be
+// applied to: the assignments it takes out and the ones it puts in
+type moveChange struct {
+	removed     map[string]*ClassAssignment
+	added       map[string]*ClassAssignment
+	removedKeys []string // sorted
+	addedKeys   []string // sorted
+}
+
+func resolveMove(solution *Solution, move *Move) *moveChange {
+	change := &moveChange{
+		removed: make(map[string]*ClassAssignment, len(move.Removed)),
+		added:   move.Added,
+	}
+
+	for _, key := range move.Removed {
+		if assignment, ok := solution.Schedule[key]; ok {
+			change.removed[key] = assignment
+			change.removedKeys = append(change.removedKeys, key)
+		}
+	}
+	for key := range move.Added {
+		change.addedKeys = append(change.addedKeys, key)
+	}
+	sort.Strings(change.removedKeys)
+	sort.Strings(change.addedKeys)
+
+	return change
+}
+
+// deltaConstraint is implemented by constraints that can work out how a move
+// changes their penalty by looking only at the assignments it touches
+type deltaConstraint interface {
+	Constraint
+	// delta returns the change in penalty when change is applied to solution.
+	// solution and its occupancy index still describe the state before the move.
+	delta(solution *Solution, change *moveChange) float64
+}
+
+// pairDelta works out the change of a penalty that adds up pair(a, b) over
+// every pair of assignments. candidates returns, from the index, the keys of
+// the assignments that can pair with a at all.
+func pairDelta(solution *Solution, change *moveChange, candidates func(*ClassAssignment) []string, pair func(a, b *ClassAssignment) float64) float64 {
+	delta := 0.0
+
+	// Pairs that disappear: removed assignments with the untouched ones and
+	// with each other
+	for i, key := range change.removedKeys {
+		assignment := change.removed[key]
+		for _, other := range candidates(assignment) {
+			if _, gone := change.removed[other]; gone {
+				continue
+			}
+			delta -= pair(assignment, solution.Schedule[other])
+		}
+		for _, otherKey := range change.removedKeys[i+1:] {
+			delta -= pair(assignment, change.removed[otherKey])
+		}
+	}
+
+	// Pairs that appear: added assignments with the untouched ones and with
+	// each other
+	for i, key := range change.addedKeys {
+		assignment := change.added[key]
+		for _, other := range candidates(assignment) {
+			if _, gone := change.removed[other]; gone {
+				continue
+			}
+			delta += pair(assignment, solution.Schedule[other])
+		}
+		for _, otherKey := range change.addedKeys[i+1:] {
+			delta += pair(assignment, change.added[otherKey])
+		}
+	}
+
+	return delta
+}
+
+// assignmentDelta works out the change of a penalty that adds up penalty(a)
+// over every assignment
+func assignmentDelta(change *moveChange, penalty func(*ClassAssignment) float64) float64 {
+	delta := 0.0
+	for _, key := range change.removedKeys {
+		delta -= penalty(change.removed[key])
+	}
+	for _, key := range change.addedKeys {
+		delta += penalty(change.added[key])
+	}
+	return delta
+}
+
+// applyMoveIncremental applies a move and updates the solution's fitness from
+// the constraints' deltas instead of re-evaluating the whole schedule.
+// Constraints without delta support are evaluated in full after the move. It
+// reports false, leaving the solution untouched, if the move is invalid.
+func (e *TimetableEngine) applyMoveIncremental(solution *Solution, move *Move) bool {
+	if solution.index == nil {
+		if !e.applyMove(solution, move) {
+			return false
+		}
+		e.evaluateSolution(solution)
+		return true
+	}
+
+	change := resolveMove(solution, move)
+
+	results := make(map[string]constraintResult, len(e.constraints))
+	full := []string{}
+	for _, name := range e.constraintOrder {
+		constraint, ok := e.constraints[name].(deltaConstraint)
+		if !ok {
+			full = append(full, name)
+			continue
+		}
+		penalty := solution.penalties[name].penalty + constraint.delta(solution, change)
+		if penalty < penaltyEpsilon {
+			penalty = 0
+		}
+		results[name] = constraintResult{violated: penalty > 0, penalty: penalty}
+	}
+
+	if !e.applyMove(solution, move) {
+		return false
+	}
+
+	for _, name := range full {
+		violated, penalty := e.constraints[name].Evaluate(solution)
+		results[name] = constraintResult{violated: violated, penalty: penalty}
+	}
+
+	solution.penalties = results
+	e.score(solution)
+	return true
+}
@@ -3,9 +3,12 @@ package optimization
 import (
 	"context"
 	"fmt"
+	"maps"
 	"math"
 	"math/rand"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,9 +23,27 @@ type TimetableEngine struct {
 	faculty     []models.Faculty
 	rooms       []models.Room
 	timeSlots   []models.TimeSlot
+	courseIndex map[uuid.UUID]models.Course
+	events      []Event
+	slotBlocks  map[uuid.UUID][]models.TimeSlot
+	expertise   map[uuid.UUID][]facultyCandidate
+	courseHours map[uuid.UUID]int
+	enrollments map[uuid.UUID]int
+	conflicts   *CourseConflictGraph
 	constraints map[string]Constraint
-	bestSolution *Solution
+	constraintOrder []string
+	weights     map[string]constraintWeight
+	seed        int64
+	bestSolution *Solution // best found by any worker, guarded by mu
+	progress    ProgressFunc
+	startedAt   time.Time
+	iterations  atomic.Int64 // search steps taken by all workers
 	mu          sync.Mutex
+
+	// Set on the per-worker copies made by Generate
+	rng    *rand.Rand
+	worker int
+	shared *TimetableEngine // engine the worker reports its best to
 }
 
 // EngineConfig holds configuration for the optimization engine
@@ -30,9 +51,14 @@ type EngineConfig struct {
 	Algorithm      string        // "hybrid", "genetic", "simulated_annealing", "tabu_search"
 	MaxIterations  int           // Maximum iterations
 	Timeout        time.Duration // Maximum runtime
-	Workers        int           // Number of parallel workers
+	Workers        int           // Number of parallel searches
 	PopulationSize int           // For genetic algorithm
 	Temperature    float64       // For simulated annealing
+	Seed           int64         // Random seed; 0 picks one from the clock
+	TabuTenure     int           // Iterations a reversed move stays tabu; 0 uses defaultTabuTenure
+
+	// RoomCompatibility overrides DefaultRoomCompatibility per course type
+	RoomCompatibility map[string][]string
 }
 
 // Solution represents a complete timetable solution
@@ -41,17 +67,47 @@ type Solution struct {
 	FitnessScore  float64
 	HardViolations int
 	SoftViolations int
+	Issues         []SchedulingIssue // courses that could not be fully scheduled
+
+	// Kept up to date by evaluateSolution and applyMoveIncremental so that
+	// moves can be scored without re-evaluating the whole schedule
+	index     *occupancy
+	penalties map[string]constraintResult // by constraint name; replaced, never modified
+}
+
+// Scheduling issue reasons
+const (
+	IssueNoQualifiedFaculty = "NO_QUALIFIED_FACULTY"
+	IssueNoSuitableRoom     = "NO_SUITABLE_ROOM"
+	IssueNoFreeSlot         = "NO_FREE_SLOT"
+)
+
+// SchedulingIssue explains why some or all meetings of a course were left out
+type SchedulingIssue struct {
+	CourseID   uuid.UUID `json:"course_id"`
+	CourseCode string    `json:"course_code"`
+	Reason     string    `json:"reason"`
+	Unplaced   int       `json:"unplaced_meetings"`
+}
+
+// facultyCandidate is a faculty member qualified to teach a course
+type facultyCandidate struct {
+	faculty           *models.Faculty
+	preferenceLevel   int
+	yearsOfExperience int
 }
 
 // ClassAssignment represents a single class assignment
 type ClassAssignment struct {
-	CourseID  uuid.UUID
-	FacultyID uuid.UUID
-	RoomID    uuid.UUID
-	DayOfWeek int
-	StartTime string
-	EndTime   string
-	TimeSlot  models.TimeSlot
+	CourseID   uuid.UUID
+	FacultyID  uuid.UUID
+	RoomID     uuid.UUID
+	DayOfWeek  int
+	StartTime  string
+	EndTime    string
+	TimeSlot   models.TimeSlot // first slot of the meeting
+	EventIndex int             // which weekly meeting of the course this is
+	Length     int             // number of consecutive slots occupied
 }
 
 // Constraint interface for all constraints
@@ -61,6 +117,12 @@ type Constraint interface {
 	GetDescription() string
 }
 
+// constraintWeight is how much a constraint's penalty counts in the fitness
+type constraintWeight struct {
+	hard   bool
+	weight float64 // multiplies the penalty of a soft constraint
+}
+
 // NewTimetableEngine creates a new optimization engine
 func NewTimetableEngine(timetableID uuid.UUID, config *EngineConfig) *TimetableEngine {
 	if config == nil {
@@ -74,10 +136,18 @@ func NewTimetableEngine(timetableID uuid.UUID, config *EngineConfig) *TimetableE
 		}
 	}
 
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
 	return &TimetableEngine{
 		timetableID: timetableID,
 		config:      config,
 		constraints: make(map[string]Constraint),
+		weights:     make(map[string]constraintWeight),
+		seed:        seed,
+		rng:         rand.New(rand.NewSource(seed)),
 		bestSolution: &Solution{
 			Schedule:      make(map[string]*ClassAssignment),
 			FitnessScore:  math.Inf(-1),
@@ -85,44 +155,133 @@ func NewTimetableEngine(timetableID uuid.UUID, config *EngineConfig) *TimetableE
 	}
 }
 
-// LoadData loads courses, faculty, rooms, and time slots
+// LoadData loads courses, faculty, rooms, and time slots. The engine keeps
+// its own copies in a fixed order (by ID, and time slots by day and start
+// time) so that a seed gives the same solution however the rows were
+// returned by the database.
 func (e *TimetableEngine) LoadData(
 	courses []models.Course,
 	faculty []models.Faculty,
 	rooms []models.Room,
 	timeSlots []models.TimeSlot,
 ) {
-	e.courses = courses
-	e.faculty = faculty
-	e.rooms = rooms
-	e.timeSlots = timeSlots
+	e.courses = append([]models.Course(nil), courses...)
+	sort.Slice(e.courses, func(i, j int) bool {
+		return e.courses[i].ID.String() < e.courses[j].ID.String()
+	})
+	e.faculty = append([]models.Faculty(nil), faculty...)
+	sort.Slice(e.faculty, func(i, j int) bool {
+		return e.faculty[i].ID.String() < e.faculty[j].ID.String()
+	})
+	e.rooms = append([]models.Room(nil), rooms...)
+	sort.Slice(e.rooms, func(i, j int) bool {
+		return e.rooms[i].ID.String() < e.rooms[j].ID.String()
+	})
+	e.timeSlots = append([]models.TimeSlot(nil), timeSlots...)
+	sort.Slice(e.timeSlots, func(i, j int) bool {
+		a, b := e.timeSlots[i], e.timeSlots[j]
+		if a.DayOfWeek != b.DayOfWeek {
+			return a.DayOfWeek < b.DayOfWeek
+		}
+		if a.StartTime != b.StartTime {
+			return a.StartTime < b.StartTime
+		}
+		return a.ID.String() < b.ID.String()
+	})
+
+	e.courseIndex = make(map[uuid.UUID]models.Course, len(e.courses))
+	for _, course := range e.courses {
+		e.courseIndex[course.ID] = course
+	}
+
+	e.events = expandEvents(e.courses)
+	e.slotBlocks = buildSlotBlocks(e.timeSlots)
+
+	e.courseHours = make(map[uuid.UUID]int)
+	for _, event := range e.events {
+		e.courseHours[event.CourseID] += event.Length
+	}
+
+	e.expertise = make(map[uuid.UUID][]facultyCandidate)
+	for i := range e.faculty {
+		for _, expertise := range e.faculty[i].CourseExpertise {
+			e.expertise[expertise.CourseID] = append(e.expertise[expertise.CourseID], facultyCandidate{
+				faculty:           &e.faculty[i],
+				preferenceLevel:   expertise.PreferenceLevel,
+				yearsOfExperience: expertise.YearsOfExperience,
+			})
+		}
+	}
+}
+
+// LoadEnrollments records how many students take each course so that rooms
+// can be matched on capacity. Dropped enrollments are ignored.
+func (e *TimetableEngine) LoadEnrollments(enrollments []models.StudentEnrollment) {
+	e.enrollments = make(map[uuid.UUID]int)
+	for _, enrollment := range enrollments {
+		if enrollment.Status == "DROPPED" {
+			continue
+		}
+		e.enrollments[enrollment.CourseID]++
+	}
+}
+
+// SetCourseConflicts tells the engine which courses share students so that
+// construction and moves keep them apart
+func (e *TimetableEngine) SetCourseConflicts(graph *CourseConflictGraph) {
+	e.conflicts = graph
 }
 
 // AddConstraint adds a constraint to the engine
 func (e *TimetableEngine) AddConstraint(name string, constraint Constraint) {
+	e.AddWeightedConstraint(name, constraint, constraint.IsHard(), 1)
+}
+
+// AddWeightedConstraint adds a constraint that counts as hard or soft as
+// given, whatever its own IsHard says. The penalty of a soft constraint is
+// multiplied by weight.
+func (e *TimetableEngine) AddWeightedConstraint(name string, constraint Constraint, hard bool, weight float64) {
+	if _, exists := e.constraints[name]; !exists {
+		e.constraintOrder = append(e.constraintOrder, name)
+		sort.Strings(e.constraintOrder)
+	}
 	e.constraints[name] = constraint
+	e.weights[name] = constraintWeight{hard: hard, weight: weight}
+}
+
+// Seed returns the random seed of this engine. Running again with the same
+// seed, data and constraints produces the same solution, provided the run is
+// not cut short by the timeout.
+func (e *TimetableEngine) Seed() int64 {
+	return e.seed
 }
 
 // Generate runs the optimization algorithm
 func (e *TimetableEngine) Generate(ctx context.Context) (*Solution, error) {
-	// startTime := time.Now() // TODO: use for metrics
+	e.startedAt = time.Now()
 
 	// Create timeout context
 	timeoutCtx, cancel := context.WithTimeout(ctx, e.config.Timeout)
 	defer cancel()
 
+	// Run independent searches in parallel and keep the best
+	return e.runWorkers(timeoutCtx)
+}
+
+// run executes the configured algorithm on one worker
+func (e *TimetableEngine) run(ctx context.Context) (*Solution, error) {
 	// Select algorithm based on configuration
 	switch e.config.Algorithm {
 	case "hybrid":
-		return e.hybridAlgorithm(timeoutCtx)
+		return e.hybridAlgorithm(ctx)
 	case "genetic":
-		return e.geneticAlgorithm(timeoutCtx)
+		return e.geneticAlgorithm(ctx)
 	case "simulated_annealing":
-		return e.simulatedAnnealing(timeoutCtx)
+		return e.simulatedAnnealing(ctx)
 	case "tabu_search":
-		return e.tabuSearch(timeoutCtx)
+		return e.tabuSearch(ctx)
 	default:
-		return e.hybridAlgorithm(timeoutCtx)
+		return e.hybridAlgorithm(ctx)
 	}
 }
 
@@ -130,6 +289,7 @@ func (e *TimetableEngine) Generate(ctx context.Context) (*Solution, error) {
 func (e *TimetableEngine) hybridAlgorithm(ctx context.Context) (*Solution, error) {
 	// Phase 1: Greedy construction (fast initial solution)
 	initialSolution := e.greedyConstruction()
+	e.report(PhaseGreedy, 0, initialSolution, initialSolution)
 
 	// Phase 2: Simulated annealing for global exploration
 	solution := e.improveWithSimulatedAnnealing(ctx, initialSolution, 100)
@@ -145,46 +305,136 @@ func (e *TimetableEngine) hybridAlgorithm(ctx context.Context) (*Solution, error
 
 // greedyConstruction creates an initial feasible solution quickly
 func (e *TimetableEngine) greedyConstruction() *Solution {
+	return e.construct(false)
+}
+
+// construct places every event in turn. With randomize set, events of equal
+// length, faculty with spare capacity and start slots are taken in random
+// order, so repeated calls give different but still mostly feasible solutions.
+func (e *TimetableEngine) construct(randomize bool) *Solution {
 	solution := &Solution{
 		Schedule: make(map[string]*ClassAssignment),
 	}
 
-	// Sort courses by complexity (lab courses, high credit hours first)
-	sortedCourses := e.sortCoursesByComplexity()
+	// Every meeting of a course is taught by the same faculty member
+	courseFaculty := make(map[uuid.UUID]*models.Faculty)
+	facultyHours := make(map[uuid.UUID]int)
+	courseDays := make(map[uuid.UUID]map[int]bool)
+	unplaced := make(map[uuid.UUID]int)
+	reasons := make(map[uuid.UUID]string)
+
+	// Place hard-to-fit meetings (long lab blocks, heavy courses) first
+	events := e.sortEventsByComplexity()
+	if randomize {
+		e.rng.Shuffle(len(events), func(i, j int) { events[i], events[j] = events[j], events[i] })
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Length > events[j].Length
+		})
+	}
+
+	for _, event := range events {
+		course := e.courseIndex[event.CourseID]
 
-	for _, course := range sortedCourses {
 		// Find suitable faculty
-		faculty := e.findSuitableFaculty(course.ID)
+		faculty, ok := courseFaculty[course.ID]
+		if !ok {
+			if randomize {
+				faculty = e.randomFaculty(course.ID, facultyHours)
+			} else {
+				faculty = e.findSuitableFaculty(course.ID, facultyHours)
+			}
+			courseFaculty[course.ID] = faculty
+			if faculty != nil {
+				facultyHours[faculty.ID] += e.courseHours[course.ID]
+			}
+		}
 		if faculty == nil {
+			unplaced[course.ID]++
+			reasons[course.ID] = IssueNoQualifiedFaculty
 			continue
 		}
 
-		// Find suitable room
-		room := e.findSuitableRoom(course.CourseType)
-		if room == nil {
+		// Find suitable rooms, best fit first
+		rooms := e.suitableRooms(course)
+		if len(rooms) == 0 {
+			unplaced[course.ID]++
+			reasons[course.ID] = IssueNoSuitableRoom
 			continue
 		}
 
-		// Find available time slot
-		for _, timeSlot := range e.timeSlots {
-			key := e.makeKey(course.ID, timeSlot.DayOfWeek, timeSlot.ID)
+		if courseDays[course.ID] == nil {
+			courseDays[course.ID] = make(map[int]bool)
+		}
 
-			// Check if slot is available
-			if !e.isSlotAvailable(solution, *faculty, *room, timeSlot) {
-				continue
-			}
+		template := &ClassAssignment{
+			CourseID:   course.ID,
+			FacultyID:  faculty.ID,
+			EventIndex: event.Index,
+			Length:     event.Length,
+		}
 
-			// Assign class
-			solution.Schedule[key] = &ClassAssignment{
-				CourseID:  course.ID,
-				FacultyID: faculty.ID,
-				RoomID:    room.ID,
-				DayOfWeek: timeSlot.DayOfWeek,
-				StartTime: timeSlot.StartTime,
-				EndTime:   timeSlot.EndTime,
-				TimeSlot:  timeSlot,
+		// Prefer days on which the course does not meet yet, then any day,
+		// and only as a last resort a time that clashes for students
+		starts := e.startSlots(event.Length)
+		if randomize {
+			e.rng.Shuffle(len(starts), func(i, j int) { starts[i], starts[j] = starts[j], starts[i] })
+		}
+
+		var placed *ClassAssignment
+		for pass := 0; pass < 3 && placed == nil; pass++ {
+			for _, timeSlot := range starts {
+				if pass == 0 && courseDays[course.ID][timeSlot.DayOfWeek] {
+					continue
+				}
+
+				candidate := e.placeAt(template, timeSlot)
+				if candidate == nil {
+					continue
+				}
+				if _, taken := solution.Schedule[e.assignmentKey(candidate)]; taken {
+					continue
+				}
+
+				// Check if slot is available for the faculty, the students and some room
+				studentCheck := course.ID
+				if pass == 2 {
+					studentCheck = uuid.Nil
+				}
+				if !e.isFree(solution, "", faculty.ID, uuid.Nil, studentCheck, candidate) {
+					continue
+				}
+				for _, room := range rooms {
+					if e.isFree(solution, "", uuid.Nil, room.ID, uuid.Nil, candidate) {
+						candidate.RoomID = room.ID
+						placed = candidate
+						break
+					}
+				}
+				if placed != nil {
+					break
+				}
 			}
-			break
+		}
+
+		if placed == nil {
+			unplaced[course.ID]++
+			reasons[course.ID] = IssueNoFreeSlot
+			continue
+		}
+
+		// Assign class
+		solution.Schedule[e.assignmentKey(placed)] = placed
+		courseDays[course.ID][placed.DayOfWeek] = true
+	}
+
+	for _, course := range e.courses {
+		if unplaced[course.ID] > 0 {
+			solution.Issues = append(solution.Issues, SchedulingIssue{
+				CourseID:   course.ID,
+				CourseCode: course.Code,
+				Reason:     reasons[course.ID],
+				Unplaced:   unplaced[course.ID],
+			})
 		}
 	}
 
@@ -197,6 +447,7 @@ func (e *TimetableEngine) simulatedAnnealing(ctx context.Context) (*Solution, er
 	current := e.greedyConstruction()
 	best := current
 	temperature := e.config.Temperature
+	e.report(PhaseGreedy, 0, current, best)
 
 	for i := 0; i < e.config.MaxIterations; i++ {
 		select {
@@ -210,7 +461,7 @@ func (e *TimetableEngine) simulatedAnnealing(ctx context.Context) (*Solution, er
 
 		// Calculate acceptance probability
 		delta := neighbor.FitnessScore - current.FitnessScore
-		if delta > 0 || rand.Float64() < math.Exp(delta/temperature) {
+		if delta > 0 || e.rng.Float64() < math.Exp(delta/temperature) {
 			current = neighbor
 
 			if current.FitnessScore > best.FitnessScore {
@@ -220,6 +471,7 @@ func (e *TimetableEngine) simulatedAnnealing(ctx context.Context) (*Solution, er
 
 		// Cool down temperature
 		temperature *= 0.995
+		e.report(PhaseSimulatedAnnealing, i+1, current, best)
 	}
 
 	return best, nil
@@ -230,6 +482,7 @@ func (e *TimetableEngine) improveWithSimulatedAnnealing(ctx context.Context, ini
 	current := initial
 	best := initial
 	temperature := 500.0
+	e.report(PhaseSimulatedAnnealing, 0, current, best)
 
 	for i := 0; i < iterations; i++ {
 		select {
@@ -241,7 +494,7 @@ func (e *TimetableEngine) improveWithSimulatedAnnealing(ctx context.Context, ini
 		neighbor := e.generateNeighbor(current)
 		delta := neighbor.FitnessScore - current.FitnessScore
 
-		if delta > 0 || rand.Float64() < math.Exp(delta/temperature) {
+		if delta > 0 || e.rng.Float64() < math.Exp(delta/temperature) {
 			current = neighbor
 			if current.FitnessScore > best.FitnessScore {
 				best = current
@@ -249,144 +502,16 @@ func (e *TimetableEngine) improveWithSimulatedAnnealing(ctx context.Context, ini
 		}
 
 		temperature *= 0.98
+		e.report(PhaseSimulatedAnnealing, i+1, current, best)
 	}
 
 	return best
 }
 
-// tabuSearch implements tabu search optimization
-func (e *TimetableEngine) tabuSearch(ctx context.Context) (*Solution, error) {
-	current := e.greedyConstruction()
-	best := current
-	tabuList := make(map[string]int)
-	tabuTenure := 10
-
-	for i := 0; i < e.config.MaxIterations; i++ {
-		select {
-		case <-ctx.Done():
-			return best, nil
-		default:
-		}
-
-		// Generate neighbors and select best non-tabu
-		neighbors := e.generateNeighbors(current, 20)
-		var bestNeighbor *Solution
-
-		for _, neighbor := range neighbors {
-			hash := e.solutionHash(neighbor)
-			if _, isTabu := tabuList[hash]; isTabu {
-				continue
-			}
-
-			if bestNeighbor == nil || neighbor.FitnessScore > bestNeighbor.FitnessScore {
-				bestNeighbor = neighbor
-			}
-		}
-
-		if bestNeighbor == nil {
-			break
-		}
-
-		// Update tabu list
-		hash := e.solutionHash(current)
-		tabuList[hash] = i + tabuTenure
-
-		// Clean old tabu entries
-		for k, v := range tabuList {
-			if v < i {
-				delete(tabuList, k)
-			}
-		}
-
-		current = bestNeighbor
-		if current.FitnessScore > best.FitnessScore {
-			best = current
-		}
-	}
-
-	return best, nil
-}
-
-// improveWithTabuSearch improves a solution using tabu search
-func (e *TimetableEngine) improveWithTabuSearch(ctx context.Context, initial *Solution, iterations int) *Solution {
-	current := initial
-	best := initial
-	tabuList := make(map[string]int)
-	tabuTenure := 5
-
-	for i := 0; i < iterations; i++ {
-		select {
-		case <-ctx.Done():
-			return best
-		default:
-		}
-
-		neighbors := e.generateNeighbors(current, 10)
-		var bestNeighbor *Solution
-
-		for _, neighbor := range neighbors {
-			hash := e.solutionHash(neighbor)
-			if _, isTabu := tabuList[hash]; !isTabu {
-				if bestNeighbor == nil || neighbor.FitnessScore > bestNeighbor.FitnessScore {
-					bestNeighbor = neighbor
-				}
-			}
-		}
-
-		if bestNeighbor != nil {
-			tabuList[e.solutionHash(current)] = i + tabuTenure
-			current = bestNeighbor
-			if current.FitnessScore > best.FitnessScore {
-				best = current
-			}
-		}
-	}
-
-	return best
-}
-
-// geneticAlgorithm implements genetic algorithm optimization
-func (e *TimetableEngine) geneticAlgorithm(ctx context.Context) (*Solution, error) {
-	// Initialize population
-	population := make([]*Solution, e.config.PopulationSize)
-	for i := 0; i < e.config.PopulationSize; i++ {
-		population[i] = e.randomSolution()
-	}
-
-	for generation := 0; generation < e.config.MaxIterations/e.config.PopulationSize; generation++ {
-		select {
-		case <-ctx.Done():
-			return e.getBestFromPopulation(population), nil
-		default:
-		}
-
-		// Selection
-		parents := e.tournamentSelection(population, e.config.PopulationSize/2)
-
-		// Crossover
-		offspring := make([]*Solution, 0)
-		for i := 0; i < len(parents)-1; i += 2 {
-			child1, child2 := e.crossover(parents[i], parents[i+1])
-			offspring = append(offspring, child1, child2)
-		}
-
-		// Mutation
-		for _, child := range offspring {
-			if rand.Float64() < 0.1 {
-				e.mutate(child)
-			}
-		}
-
-		// Replacement
-		population = append(parents[:len(parents)/2], offspring...)[:e.config.PopulationSize]
-	}
-
-	return e.getBestFromPopulation(population), nil
-}
-
 // hillClimbing performs simple hill climbing
 func (e *TimetableEngine) hillClimbing(ctx context.Context, initial *Solution, iterations int) *Solution {
 	current := initial
+	e.report(PhaseHillClimbing, 0, current, current)
 
 	for i := 0; i < iterations; i++ {
 		select {
@@ -401,6 +526,7 @@ func (e *TimetableEngine) hillClimbing(ctx context.Context, initial *Solution, i
 		} else {
 			break // Local optimum reached
 		}
+		e.report(PhaseHillClimbing, i+1, current, current)
 	}
 
 	return current
@@ -408,23 +534,40 @@ func (e *TimetableEngine) hillClimbing(ctx context.Context, initial *Solution, i
 
 // Helper methods
 
+// evaluateSolution evaluates every constraint on the whole schedule and
+// rebuilds the occupancy index used by incremental evaluation
 func (e *TimetableEngine) evaluateSolution(solution *Solution) float64 {
+	solution.index = buildOccupancy(solution)
+
+	results := make(map[string]constraintResult, len(e.constraints))
+	// Fixed order, so penalties are summed identically on every run
+	for _, name := range e.constraintOrder {
+		violated, penalty := e.constraints[name].Evaluate(solution)
+		results[name] = constraintResult{violated: violated, penalty: penalty}
+	}
+	solution.penalties = results
+
+	return e.score(solution)
+}
+
+// score sets the solution's fitness and violation counts from its
+// per-constraint results
+func (e *TimetableEngine) score(solution *Solution) float64 {
 	score := 1000.0
 	hardViolations := 0
 	softViolations := 0
 
-	for name, constraint := range e.constraints {
-		violated, penalty := constraint.Evaluate(solution)
-		if violated {
-			if constraint.IsHard() {
-				hardViolations++
-				score -= 1000 * penalty
-			} else {
-				softViolations++
-				score -= penalty
-			}
+	for _, name := range e.constraintOrder {
+		result := solution.penalties[name]
+		if !result.violated {
+			continue
+		}
+		if e.weights[name].hard {
+			hardViolations++
+		} else {
+			softViolations++
 		}
-		_ = name // for debugging
+		score -= e.cost(name, result)
 	}
 
 	solution.HardViolations = hardViolations
@@ -434,79 +577,138 @@ func (e *TimetableEngine) evaluateSolution(solution *Solution) float64 {
 	return score
 }
 
+// cost is how much a constraint's result takes off the fitness score. Hard
+// constraints cost 1000 per unit of penalty, soft ones their weight.
+func (e *TimetableEngine) cost(name string, result constraintResult) float64 {
+	if !result.violated {
+		return 0
+	}
+	weight := e.weights[name]
+	if weight.hard {
+		return 1000 * result.penalty
+	}
+	return weight.weight * result.penalty
+}
+
 func (e *TimetableEngine) makeKey(courseID uuid.UUID, day int, slotID uuid.UUID) string {
 	return fmt.Sprintf("%s:%d:%s", courseID.String(), day, slotID.String())
 }
 
-func (e *TimetableEngine) isSlotAvailable(solution *Solution, faculty models.Faculty, room models.Room, slot models.TimeSlot) bool {
-	// Check faculty availability
-	for _, assignment := range solution.Schedule {
-		if assignment.FacultyID == faculty.ID && assignment.DayOfWeek == slot.DayOfWeek {
-			if e.timeSlotsOverlap(assignment.StartTime, assignment.EndTime, slot.StartTime, slot.EndTime) {
-				return false
-			}
-		}
+// findSuitableFaculty picks the best qualified faculty member for a course.
+// Candidates are ranked by preference level, then years of experience, then
+// remaining weekly capacity; those without enough capacity left for the
+// course's hours are only used when nobody else is qualified.
+func (e *TimetableEngine) findSuitableFaculty(courseID uuid.UUID, assignedHours map[uuid.UUID]int) *models.Faculty {
+	candidates := e.rankedFaculty(courseID, assignedHours)
+	if len(candidates) == 0 {
+		return nil
 	}
 
-	// Check room availability
-	for _, assignment := range solution.Schedule {
-		if assignment.RoomID == room.ID && assignment.DayOfWeek == slot.DayOfWeek {
-			if e.timeSlotsOverlap(assignment.StartTime, assignment.EndTime, slot.StartTime, slot.EndTime) {
-				return false
-			}
+	needed := e.courseHours[courseID]
+	for _, candidate := range candidates {
+		if candidate.faculty.MaxHoursPerWeek-assignedHours[candidate.faculty.ID] >= needed {
+			return candidate.faculty
 		}
 	}
 
-	return true
+	return candidates[0].faculty
 }
 
-func (e *TimetableEngine) timeSlotsOverlap(start1, end1, start2, end2 string) bool {
-	// Simple string comparison (assumes HH:MM format)
-	return !(end1 <= start2 || end2 <= start1)
-}
+// randomFaculty picks a random qualified faculty member among those with
+// enough spare hours for the course, falling back to findSuitableFaculty
+func (e *TimetableEngine) randomFaculty(courseID uuid.UUID, assignedHours map[uuid.UUID]int) *models.Faculty {
+	needed := e.courseHours[courseID]
 
-func (e *TimetableEngine) findSuitableFaculty(courseID uuid.UUID) *models.Faculty {
-	// Find faculty with expertise in this course
-	for i := range e.faculty {
-		// Check if faculty has expertise (this would be a DB query in real implementation)
-		return &e.faculty[i]
+	available := []*models.Faculty{}
+	for _, candidate := range e.rankedFaculty(courseID, assignedHours) {
+		if candidate.faculty.MaxHoursPerWeek-assignedHours[candidate.faculty.ID] >= needed {
+			available = append(available, candidate.faculty)
+		}
 	}
-	return nil
+	if len(available) == 0 {
+		return e.findSuitableFaculty(courseID, assignedHours)
+	}
+
+	return available[e.rng.Intn(len(available))]
 }
 
-func (e *TimetableEngine) findSuitableRoom(courseType string) *models.Room {
-	for i := range e.rooms {
-		room := &e.rooms[i]
-		if courseType == "LAB" && room.RoomType == "LAB" {
-			return room
+// rankedFaculty returns the course's qualified faculty, best candidate first
+func (e *TimetableEngine) rankedFaculty(courseID uuid.UUID, assignedHours map[uuid.UUID]int) []facultyCandidate {
+	candidates := make([]facultyCandidate, len(e.expertise[courseID]))
+	copy(candidates, e.expertise[courseID])
+
+	remaining := func(c facultyCandidate) int {
+		return c.faculty.MaxHoursPerWeek - assignedHours[c.faculty.ID]
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.preferenceLevel != b.preferenceLevel {
+			return a.preferenceLevel > b.preferenceLevel
+		}
+		if a.yearsOfExperience != b.yearsOfExperience {
+			return a.yearsOfExperience > b.yearsOfExperience
 		}
-		if courseType == "THEORY" && room.RoomType == "CLASSROOM" {
-			return room
+		if remaining(a) != remaining(b) {
+			return remaining(a) > remaining(b)
 		}
+		return a.faculty.ID.String() < b.faculty.ID.String()
+	})
+
+	return candidates
+}
+
+// qualifiedFaculty returns the faculty members with expertise in the course
+func (e *TimetableEngine) qualifiedFaculty(courseID uuid.UUID) []*models.Faculty {
+	qualified := make([]*models.Faculty, 0, len(e.expertise[courseID]))
+	for _, candidate := range e.expertise[courseID] {
+		qualified = append(qualified, candidate.faculty)
 	}
-	return nil
+	return qualified
 }
 
-func (e *TimetableEngine) sortCoursesByComplexity() []models.Course {
-	// Sort by credits (descending) and labs first
-	sorted := make([]models.Course, len(e.courses))
-	copy(sorted, e.courses)
+// sortEventsByComplexity orders events so that long blocks and courses with
+// many weekly hours or credits are placed first
+func (e *TimetableEngine) sortEventsByComplexity() []Event {
+	sorted := make([]Event, len(e.events))
+	copy(sorted, e.events)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Length != b.Length {
+			return a.Length > b.Length
+		}
+		courseA, courseB := e.courseIndex[a.CourseID], e.courseIndex[b.CourseID]
+		if courseA.HoursPerWeek != courseB.HoursPerWeek {
+			return courseA.HoursPerWeek > courseB.HoursPerWeek
+		}
+		if courseA.Credits != courseB.Credits {
+			return courseA.Credits > courseB.Credits
+		}
+		return a.ID < b.ID
+	})
+
 	return sorted
 }
 
 func (e *TimetableEngine) generateNeighbor(solution *Solution) *Solution {
+	neighbor, _ := e.generateNeighborMove(solution)
+	return neighbor
+}
+
+// generateNeighborMove returns a neighbouring solution together with the move
+// that produced it. The move is nil when no move was possible.
+func (e *TimetableEngine) generateNeighborMove(solution *Solution) (*Solution, *Move) {
 	// Create a copy
 	neighbor := e.copySolution(solution)
 
-	// Random swap or move
-	if rand.Float64() < 0.5 {
-		e.swapRandomAssignments(neighbor)
-	} else {
-		e.moveRandomAssignment(neighbor)
+	// Apply a random swap, relocation, room/faculty change or Kempe chain
+	move := e.randomMove(neighbor)
+	if move != nil && !e.applyMoveIncremental(neighbor, move) {
+		move = nil
 	}
 
-	neighbor.FitnessScore = e.evaluateSolution(neighbor)
-	return neighbor
+	return neighbor, move
 }
 
 func (e *TimetableEngine) generateNeighbors(solution *Solution, count int) []*Solution {
@@ -528,68 +730,20 @@ func (e *TimetableEngine) generateBestNeighbor(solution *Solution) *Solution {
 	return best
 }
 
+// copySolution returns a solution that moves can change without touching the
+// original. Assignments, the sorted key list and the penalties are shared, as
+// they are replaced rather than modified.
 func (e *TimetableEngine) copySolution(solution *Solution) *Solution {
 	newSolution := &Solution{
-		Schedule:       make(map[string]*ClassAssignment),
+		Schedule:       maps.Clone(solution.Schedule),
 		FitnessScore:   solution.FitnessScore,
 		HardViolations: solution.HardViolations,
 		SoftViolations: solution.SoftViolations,
+		Issues:         solution.Issues,
+		penalties:      solution.penalties,
 	}
-	for k, v := range solution.Schedule {
-		newSolution.Schedule[k] = v
+	if solution.index != nil {
+		newSolution.index = solution.index.clone()
 	}
 	return newSolution
 }
-
-func (e *TimetableEngine) swapRandomAssignments(solution *Solution) {
-	// Implementation of swapping two random assignments
-}
-
-func (e *TimetableEngine) moveRandomAssignment(solution *Solution) {
-	// Implementation of moving one assignment to a different slot
-}
-
-func (e *TimetableEngine) solutionHash(solution *Solution) string {
-	// Create a hash of the solution for tabu list
-	return fmt.Sprintf("%f", solution.FitnessScore)
-}
-
-func (e *TimetableEngine) randomSolution() *Solution {
-	return e.greedyConstruction()
-}
-
-func (e *TimetableEngine) tournamentSelection(population []*Solution, count int) []*Solution {
-	selected := make([]*Solution, count)
-	for i := 0; i < count; i++ {
-		a := population[rand.Intn(len(population))]
-		b := population[rand.Intn(len(population))]
-		if a.FitnessScore > b.FitnessScore {
-			selected[i] = a
-		} else {
-			selected[i] = b
-		}
-	}
-	return selected
-}
-
-func (e *TimetableEngine) crossover(parent1, parent2 *Solution) (*Solution, *Solution) {
-	// Simple one-point crossover
-	child1 := e.copySolution(parent1)
-	child2 := e.copySolution(parent2)
-	return child1, child2
-}
-
-func (e *TimetableEngine) mutate(solution *Solution) {
-	e.moveRandomAssignment(solution)
-	solution.FitnessScore = e.evaluateSolution(solution)
-}
-
-func (e *TimetableEngine) getBestFromPopulation(population []*Solution) *Solution {
-	best := population[0]
-	for _, solution := range population[1:] {
-		if solution.FitnessScore > best.FitnessScore {
-			best = solution
-		}
-	}
-	return best
-}
@@ -0,0 +1,359 @@
+package optimization
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+// testID returns a stable UUID for a name so fixtures are the same every run
+func testID(name string) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(name))
+}
+
+// testData is a small department: theory and lab courses, faculty qualified
+// for two courses each, a few rooms and a five day week of hourly slots
+type testData struct {
+	courses   []models.Course
+	faculty   []models.Faculty
+	rooms     []models.Room
+	timeSlots []models.TimeSlot
+}
+
+func newTestData() testData {
+	var data testData
+
+	for i := 0; i < 8; i++ {
+		course := models.Course{
+			Code:         fmt.Sprintf("C%d", i),
+			Name:         fmt.Sprintf("Course %d", i),
+			CourseType:   "THEORY",
+			Credits:      3,
+			HoursPerWeek: 3,
+			IsActive:     true,
+		}
+		if i%4 == 3 {
+			course.CourseType = "LAB"
+			course.HoursPerWeek = 2
+		}
+		course.ID = testID("course" + course.Code)
+		data.courses = append(data.courses, course)
+	}
+
+	for i := 0; i < 5; i++ {
+		faculty := models.Faculty{
+			FirstName:       fmt.Sprintf("F%d", i),
+			MaxHoursPerWeek: 12,
+			IsActive:        true,
+		}
+		faculty.ID = testID(fmt.Sprintf("faculty%d", i))
+		for _, c := range []int{i, (i + 3) % len(data.courses)} {
+			faculty.CourseExpertise = append(faculty.CourseExpertise, models.FacultyCourseExpertise{
+				FacultyID:       faculty.ID,
+				CourseID:        data.courses[c].ID,
+				PreferenceLevel: 1 + c%3,
+			})
+		}
+		data.faculty = append(data.faculty, faculty)
+	}
+
+	for i, roomType := range []string{"CLASSROOM", "CLASSROOM", "LAB", "SEMINAR_HALL"} {
+		room := models.Room{
+			RoomNumber:  fmt.Sprintf("R%d", i),
+			RoomType:    roomType,
+			Capacity:    40 + 10*i,
+			IsAvailable: true,
+		}
+		room.ID = testID("room" + room.RoomNumber)
+		data.rooms = append(data.rooms, room)
+	}
+
+	for day := 1; day <= 5; day++ {
+		for hour := 9; hour < 15; hour++ {
+			slot := models.TimeSlot{
+				DayOfWeek: day,
+				StartTime: fmt.Sprintf("%02d:00", hour),
+				EndTime:   fmt.Sprintf("%02d:00", hour+1),
+				SlotType:  "REGULAR",
+			}
+			slot.ID = testID(fmt.Sprintf("slot%d-%d", day, hour))
+			data.timeSlots = append(data.timeSlots, slot)
+		}
+	}
+
+	return data
+}
+
+// shuffled returns a copy of the data with every list in a random order, as
+// the database may return the rows
+func (d testData) shuffled(rng *rand.Rand) testData {
+	shuffled := testData{
+		courses:   append([]models.Course(nil), d.courses...),
+		faculty:   append([]models.Faculty(nil), d.faculty...),
+		rooms:     append([]models.Room(nil), d.rooms...),
+		timeSlots: append([]models.TimeSlot(nil), d.timeSlots...),
+	}
+	rng.Shuffle(len(shuffled.courses), func(i, j int) {
+		shuffled.courses[i], shuffled.courses[j] = shuffled.courses[j], shuffled.courses[i]
+	})
+	rng.Shuffle(len(shuffled.faculty), func(i, j int) {
+		shuffled.faculty[i], shuffled.faculty[j] = shuffled.faculty[j], shuffled.faculty[i]
+	})
+	rng.Shuffle(len(shuffled.rooms), func(i, j int) {
+		shuffled.rooms[i], shuffled.rooms[j] = shuffled.rooms[j], shuffled.rooms[i]
+	})
+	rng.Shuffle(len(shuffled.timeSlots), func(i, j int) {
+		shuffled.timeSlots[i], shuffled.timeSlots[j] = shuffled.timeSlots[j], shuffled.timeSlots[i]
+	})
+	return shuffled
+}
+
+// generate runs the engine on the data with the default constraints and
+// returns the serialized solution
+func generate(t *testing.T, data testData, algorithm string, seed int64) string {
+	t.Helper()
+
+	out, err := json.Marshal(solve(t, data, algorithm, seed))
+	if err != nil {
+		t.Fatalf("marshal solution: %v", err)
+	}
+	return string(out)
+}
+
+// solve runs the engine on the data with the default constraints
+func solve(t *testing.T, data testData, algorithm string, seed int64) *Solution {
+	t.Helper()
+
+	solution, err := newTestEngine(t, data, algorithm, seed).Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(solution.Schedule) == 0 {
+		t.Fatal("Generate returned an empty schedule")
+	}
+	return solution
+}
+
+// newTestEngine loads the data and the default constraints into an engine
+func newTestEngine(t *testing.T, data testData, algorithm string, seed int64) *TimetableEngine {
+	t.Helper()
+
+	engine := NewTimetableEngine(testID("timetable"), &EngineConfig{
+		Algorithm:      algorithm,
+		MaxIterations:  300,
+		Timeout:        time.Minute,
+		Workers:        2,
+		PopulationSize: 10,
+		Temperature:    100,
+		Seed:           seed,
+	})
+	engine.LoadData(data.courses, data.faculty, data.rooms, data.timeSlots)
+	err := engine.LoadConstraints(&ConstraintInput{
+		Courses: data.courses,
+		Faculty: data.faculty,
+		Rooms:   data.rooms,
+	}, nil)
+	if err != nil {
+		t.Fatalf("LoadConstraints: %v", err)
+	}
+	return engine
+}
+
+func TestGenerateIsDeterministicForShuffledInput(t *testing.T) {
+	data := newTestData()
+	rng := rand.New(rand.NewSource(1))
+
+	for _, algorithm := range []string{"hybrid", "genetic", "simulated_annealing", "tabu_search"} {
+		t.Run(algorithm, func(t *testing.T) {
+			want := generate(t, data, algorithm, 42)
+			for i := 0; i < 3; i++ {
+				if got := generate(t, data.shuffled(rng), algorithm, 42); got != want {
+					t.Fatalf("shuffle %d: same seed gave a different solution\nwant %s\ngot  %s", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestChangeFacultyMovesWholeCourse(t *testing.T) {
+	engine := newTestEngine(t, newTestData(), "simulated_annealing", 3)
+	solution := engine.greedyConstruction()
+	engine.evaluateSolution(solution)
+
+	moved := 0
+	for i := 0; i < 200; i++ {
+		move := engine.changeRandomFaculty(solution)
+		if move == nil || !engine.applyMove(solution, move) {
+			continue
+		}
+		moved++
+
+		facultyOf := make(map[uuid.UUID]uuid.UUID)
+		for key, assignment := range solution.Schedule {
+			faculty, ok := facultyOf[assignment.CourseID]
+			if ok && faculty != assignment.FacultyID {
+				t.Fatalf("move %d: %s is taught by %s, other meetings of the course by %s", i, key, assignment.FacultyID, faculty)
+			}
+			facultyOf[assignment.CourseID] = assignment.FacultyID
+		}
+	}
+	if moved == 0 {
+		t.Fatal("no faculty change was possible")
+	}
+}
+
+func TestEvaluateRebuiltSolution(t *testing.T) {
+	engine := newTestEngine(t, newTestData(), "simulated_annealing", 7)
+	solution, err := engine.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	// A stored schedule carries only the assignments
+	rebuilt := &Solution{Schedule: make(map[string]*ClassAssignment, len(solution.Schedule))}
+	for key, assignment := range solution.Schedule {
+		copied := *assignment
+		rebuilt.Schedule[key] = &copied
+	}
+	engine.Evaluate(rebuilt)
+
+	if rebuilt.FitnessScore != solution.FitnessScore ||
+		rebuilt.HardViolations != solution.HardViolations ||
+		rebuilt.SoftViolations != solution.SoftViolations {
+		t.Fatalf("rebuilt solution scored %v (%d hard, %d soft), want %v (%d hard, %d soft)",
+			rebuilt.FitnessScore, rebuilt.HardViolations, rebuilt.SoftViolations,
+			solution.FitnessScore, solution.HardViolations, solution.SoftViolations)
+	}
+}
+
+func TestMovesKeepKeysSorted(t *testing.T) {
+	engine := newTestEngine(t, newTestData(), "simulated_annealing", 5)
+	solution := engine.greedyConstruction()
+	engine.evaluateSolution(solution)
+
+	for i := 0; i < 500; i++ {
+		neighbor, move := engine.generateNeighborMove(solution)
+		if move == nil {
+			continue
+		}
+
+		want := make([]string, 0, len(neighbor.Schedule))
+		for key := range neighbor.Schedule {
+			want = append(want, key)
+		}
+		sort.Strings(want)
+		if got := sortedKeys(neighbor); !slices.Equal(got, want) {
+			t.Fatalf("after %s move %d the key list is %v, want %v", move.Operator, i, got, want)
+		}
+		solution = neighbor
+	}
+}
+
+func TestRankedFaculty(t *testing.T) {
+	data := newTestData()
+	course := data.courses[0]
+
+	// Candidates by preference, years of experience and weekly hours
+	data.faculty = nil
+	for _, f := range []struct {
+		name                   string
+		preference, experience int
+		maxHours               int
+	}{
+		{"junior", 5, 2, 10},
+		{"senior", 5, 8, 10},
+		{"reluctant", 3, 20, 20},
+		{"senior-free", 5, 8, 20},
+	} {
+		faculty := models.Faculty{FirstName: f.name, MaxHoursPerWeek: f.maxHours, IsActive: true}
+		faculty.ID = testID("faculty" + f.name)
+		faculty.CourseExpertise = []models.FacultyCourseExpertise{{
+			FacultyID:         faculty.ID,
+			CourseID:          course.ID,
+			PreferenceLevel:   f.preference,
+			YearsOfExperience: f.experience,
+		}}
+		data.faculty = append(data.faculty, faculty)
+	}
+	engine := newTestEngine(t, data, "simulated_annealing", 1)
+
+	names := func(candidates []facultyCandidate) []string {
+		got := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			got = append(got, candidate.faculty.FirstName)
+		}
+		return got
+	}
+	hours := func(assigned map[string]int) map[uuid.UUID]int {
+		byID := make(map[uuid.UUID]int, len(assigned))
+		for name, h := range assigned {
+			byID[testID("faculty"+name)] = h
+		}
+		return byID
+	}
+
+	tests := []struct {
+		assigned map[string]int
+		ranking  []string
+		chosen   string
+	}{
+		{nil, []string{"senior-free", "senior", "junior", "reluctant"}, "senior-free"},
+		// Remaining hours only break ties
+		{map[string]int{"senior-free": 15}, []string{"senior", "senior-free", "junior", "reluctant"}, "senior"},
+		// The best candidate with room for the course's 3 hours
+		{map[string]int{"senior-free": 18, "senior": 9}, []string{"senior-free", "senior", "junior", "reluctant"}, "junior"},
+		{map[string]int{"senior-free": 18, "senior": 9, "junior": 8}, []string{"senior-free", "senior", "junior", "reluctant"}, "reluctant"},
+		// Everyone is full: the best ranked still teaches
+		{map[string]int{"senior-free": 20, "senior": 10, "junior": 10, "reluctant": 20}, []string{"senior-free", "senior", "junior", "reluctant"}, "senior-free"},
+	}
+	for _, tt := range tests {
+		assigned := hours(tt.assigned)
+		if got := names(engine.rankedFaculty(course.ID, assigned)); !slices.Equal(got, tt.ranking) {
+			t.Errorf("with %v assigned the ranking is %v, want %v", tt.assigned, got, tt.ranking)
+		}
+		if got := engine.findSuitableFaculty(course.ID, assigned); got == nil || got.FirstName != tt.chosen {
+			t.Errorf("with %v assigned the chosen faculty member is %+v, want %s", tt.assigned, got, tt.chosen)
+		}
+	}
+
+	if got := engine.rankedFaculty(data.courses[1].ID, nil); len(got) != 0 {
+		t.Errorf("unqualified course has candidates %v", names(got))
+	}
+	if got := engine.findSuitableFaculty(data.courses[1].ID, nil); got != nil {
+		t.Errorf("unqualified course got faculty member %s", got.FirstName)
+	}
+}
+
+func TestCourseWithoutQualifiedFacultyIsReported(t *testing.T) {
+	data := newTestData()
+	course := models.Course{
+		Code:         "C8",
+		Name:         "Course 8",
+		CourseType:   "THEORY",
+		Credits:      2,
+		HoursPerWeek: 2,
+		IsActive:     true,
+	}
+	course.ID = testID("course" + course.Code)
+	data.courses = append(data.courses, course)
+
+	solution := solve(t, data, "simulated_annealing", 9)
+
+	for _, assignment := range solution.Schedule {
+		if assignment.CourseID == course.ID {
+			t.Fatalf("C8 was scheduled without a qualified faculty member: %+v", assignment)
+		}
+	}
+	want := SchedulingIssue{CourseID: course.ID, CourseCode: "C8", Reason: IssueNoQualifiedFaculty, Unplaced: 2}
+	if !slices.Contains(solution.Issues, want) {
+		t.Fatalf("issues are %+v, want %+v among them", solution.Issues, want)
+	}
+}
@@ -0,0 +1,147 @@
+package optimization
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+// maxBlockLength is the longest contiguous block a lab or practical meeting
+// is allowed to occupy; longer weekly hours are split into several blocks
+const maxBlockLength = 3
+
+// Event is a single weekly meeting of a course that the engine must place
+type Event struct {
+	ID       string // "course_id#index"
+	CourseID uuid.UUID
+	Index    int // meeting number within the week, starting at 0
+	Length   int // number of consecutive time slots the meeting occupies
+}
+
+// expandEvents turns every course into the meetings it needs each week. Theory
+// style courses meet once per weekly hour, while lab and practical courses get
+// contiguous blocks of up to maxBlockLength slots.
+func expandEvents(courses []models.Course) []Event {
+	events := []Event{}
+
+	for _, course := range courses {
+		hours := course.HoursPerWeek
+		if hours <= 0 {
+			hours = course.Credits
+		}
+		if hours <= 0 {
+			hours = 1
+		}
+
+		lengths := []int{}
+		if isBlockCourse(course.CourseType) {
+			blocks := (hours + maxBlockLength - 1) / maxBlockLength
+			for i := 0; i < blocks; i++ {
+				length := hours / blocks
+				if i < hours%blocks {
+					length++
+				}
+				lengths = append(lengths, length)
+			}
+		} else {
+			for i := 0; i < hours; i++ {
+				lengths = append(lengths, 1)
+			}
+		}
+
+		for i, length := range lengths {
+			events = append(events, Event{
+				ID:       fmt.Sprintf("%s#%d", course.ID.String(), i),
+				CourseID: course.ID,
+				Index:    i,
+				Length:   length,
+			})
+		}
+	}
+
+	return events
+}
+
+// isBlockCourse reports whether a course type is taught in contiguous blocks
+func isBlockCourse(courseType string) bool {
+	return courseType == "LAB" || courseType == "PRACTICAL"
+}
+
+// isTeachingSlot reports whether classes may be placed in a time slot. Breaks,
+// lunch and special slots are left free.
+func isTeachingSlot(slot models.TimeSlot) bool {
+	return slot.SlotType == "" || slot.SlotType == "REGULAR"
+}
+
+// buildSlotBlocks indexes, for every teaching slot, the run of consecutive
+// teaching slots that starts there. blocks[slotID][n] is the (n+1)th slot of
+// the run, so a meeting of length L can start at a slot when the run has at
+// least L entries.
+func buildSlotBlocks(timeSlots []models.TimeSlot) map[uuid.UUID][]models.TimeSlot {
+	byDay := make(map[int][]models.TimeSlot)
+	for _, slot := range timeSlots {
+		if isTeachingSlot(slot) {
+			byDay[slot.DayOfWeek] = append(byDay[slot.DayOfWeek], slot)
+		}
+	}
+
+	blocks := make(map[uuid.UUID][]models.TimeSlot)
+	for _, slots := range byDay {
+		sort.Slice(slots, func(i, j int) bool {
+			return slots[i].StartTime < slots[j].StartTime
+		})
+
+		for i := range slots {
+			run := []models.TimeSlot{slots[i]}
+			for j := i + 1; j < len(slots) && len(run) < maxBlockLength; j++ {
+				if slots[j].StartTime != run[len(run)-1].EndTime {
+					break
+				}
+				run = append(run, slots[j])
+			}
+			blocks[slots[i].ID] = run
+		}
+	}
+
+	return blocks
+}
+
+// startSlots returns the slots where a meeting of the given length fits, in
+// time slot order
+func (e *TimetableEngine) startSlots(length int) []models.TimeSlot {
+	starts := []models.TimeSlot{}
+	for _, slot := range e.timeSlots {
+		if run, ok := e.slotBlocks[slot.ID]; ok && len(run) >= length {
+			starts = append(starts, slot)
+		}
+	}
+	return starts
+}
+
+// placeAt returns a copy of the assignment starting at the given slot, or nil
+// if its block does not fit there
+func (e *TimetableEngine) placeAt(assignment *ClassAssignment, start models.TimeSlot) *ClassAssignment {
+	length := assignment.slotCount()
+
+	run, ok := e.slotBlocks[start.ID]
+	if !ok || len(run) < length {
+		return nil
+	}
+
+	moved := *assignment
+	moved.DayOfWeek = start.DayOfWeek
+	moved.StartTime = start.StartTime
+	moved.EndTime = run[length-1].EndTime
+	moved.TimeSlot = start
+	return &moved
+}
+
+// slotCount returns how many time slots the assignment occupies
+func (a *ClassAssignment) slotCount() int {
+	if a.Length < 1 {
+		return 1
+	}
+	return a.Length
+}
@@ -0,0 +1,144 @@
+package optimization
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestExpandEvents(t *testing.T) {
+	tests := []struct {
+		courseType     string
+		hours, credits int
+		want           []int // lengths of the meetings
+	}{
+		{"THEORY", 3, 3, []int{1, 1, 1}},
+		{"SEMINAR", 2, 4, []int{1, 1}},
+		{"THEORY", 0, 2, []int{1, 1}},
+		{"THEORY", 0, 0, []int{1}},
+		{"LAB", 2, 1, []int{2}},
+		{"LAB", 3, 2, []int{3}},
+		{"LAB", 4, 2, []int{2, 2}},
+		{"LAB", 5, 2, []int{3, 2}},
+		{"PRACTICAL", 7, 3, []int{3, 2, 2}},
+	}
+
+	for _, tt := range tests {
+		course := models.Course{CourseType: tt.courseType, HoursPerWeek: tt.hours, Credits: tt.credits}
+		course.ID = testID(fmt.Sprintf("%s-%d-%d", tt.courseType, tt.hours, tt.credits))
+
+		events := expandEvents([]models.Course{course})
+		lengths := make([]int, 0, len(events))
+		for i, event := range events {
+			lengths = append(lengths, event.Length)
+			if event.CourseID != course.ID || event.Index != i || event.ID != fmt.Sprintf("%s#%d", course.ID, i) {
+				t.Errorf("%s of %d hours: meeting %d is %+v", tt.courseType, tt.hours, i, event)
+			}
+		}
+		if !slices.Equal(lengths, tt.want) {
+			t.Errorf("%s of %d hours and %d credits meets for %v, want %v", tt.courseType, tt.hours, tt.credits, lengths, tt.want)
+		}
+	}
+}
+
+func TestBuildSlotBlocks(t *testing.T) {
+	// Monday has a break at 11:00; Tuesday starts where Monday ends
+	var timeSlots []models.TimeSlot
+	slot := func(day, hour int, slotType string) {
+		s := models.TimeSlot{
+			DayOfWeek: day,
+			StartTime: fmt.Sprintf("%02d:00", hour),
+			EndTime:   fmt.Sprintf("%02d:00", hour+1),
+			SlotType:  slotType,
+		}
+		s.ID = testID(fmt.Sprintf("%d-%02d", day, hour))
+		timeSlots = append(timeSlots, s)
+	}
+	slot(1, 9, "REGULAR")
+	slot(1, 10, "REGULAR")
+	slot(1, 11, "BREAK")
+	slot(1, 12, "REGULAR")
+	slot(1, 13, "REGULAR")
+	for hour := 14; hour < 18; hour++ {
+		slot(2, hour, "")
+	}
+	rand.New(rand.NewSource(1)).Shuffle(len(timeSlots), func(i, j int) {
+		timeSlots[i], timeSlots[j] = timeSlots[j], timeSlots[i]
+	})
+
+	want := map[string][]string{
+		"1-09": {"1-09", "1-10"},
+		"1-10": {"1-10"},
+		"1-12": {"1-12", "1-13"},
+		"1-13": {"1-13"},
+		"2-14": {"2-14", "2-15", "2-16"},
+		"2-15": {"2-15", "2-16", "2-17"},
+		"2-16": {"2-16", "2-17"},
+		"2-17": {"2-17"},
+	}
+
+	blocks := buildSlotBlocks(timeSlots)
+	if len(blocks) != len(want) {
+		t.Errorf("got runs from %d slots, want %d", len(blocks), len(want))
+	}
+	for start, names := range want {
+		var got []string
+		for _, s := range blocks[testID(start)] {
+			got = append(got, fmt.Sprintf("%d-%s", s.DayOfWeek, s.StartTime[:2]))
+		}
+		if !slices.Equal(got, names) {
+			t.Errorf("run from %s is %v, want %v", start, got, names)
+		}
+	}
+	if _, ok := blocks[testID("1-11")]; ok {
+		t.Error("the break starts a run")
+	}
+}
+
+func TestLabMeetingsAreContiguousBlocks(t *testing.T) {
+	data := newTestData()
+	data.courses[3].HoursPerWeek = 3
+	data.courses[7].HoursPerWeek = 4
+	for i := range data.timeSlots {
+		if data.timeSlots[i].StartTime == "12:00" {
+			data.timeSlots[i].SlotType = "BREAK"
+		}
+	}
+
+	solution := solve(t, data, "simulated_annealing", 11)
+
+	lengths := map[string][]int{}
+	for key, assignment := range solution.Schedule {
+		course := data.courses[slices.IndexFunc(data.courses, func(c models.Course) bool { return c.ID == assignment.CourseID })]
+		lengths[course.Code] = append(lengths[course.Code], assignment.slotCount())
+
+		start, err := ParseClock(assignment.StartTime)
+		if err != nil {
+			t.Fatalf("%s starts at %q: %v", key, assignment.StartTime, err)
+		}
+		end, err := ParseClock(assignment.EndTime)
+		if err != nil {
+			t.Fatalf("%s ends at %q: %v", key, assignment.EndTime, err)
+		}
+		if end-start != 60*assignment.slotCount() {
+			t.Errorf("%s of %d slots runs %s-%s", key, assignment.slotCount(), assignment.StartTime, assignment.EndTime)
+		}
+		if start < 12*60 && end > 12*60 {
+			t.Errorf("%s runs %s-%s across the break", key, assignment.StartTime, assignment.EndTime)
+		}
+		if assignment.TimeSlot.DayOfWeek != assignment.DayOfWeek || assignment.TimeSlot.StartTime != assignment.StartTime {
+			t.Errorf("%s on day %d at %s starts in slot %+v", key, assignment.DayOfWeek, assignment.StartTime, assignment.TimeSlot)
+		}
+	}
+
+	for code, want := range map[string][]int{"C0": {1, 1, 1}, "C3": {3}, "C7": {2, 2}} {
+		got := lengths[code]
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s meets in blocks of %v, want %v", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package optimization
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// mutationRate is the chance that a child gets one random move after crossover
+const mutationRate = 0.1
+
+// geneticAlgorithm implements genetic algorithm optimization. The population
+// starts from the greedy solution plus randomised constructions. Each
+// generation keeps the best tenth unchanged and fills the rest with repaired
+// crossover children of tournament-selected parents.
+func (e *TimetableEngine) geneticAlgorithm(ctx context.Context) (*Solution, error) {
+	size := e.config.PopulationSize
+	if size < 2 {
+		size = 2
+	}
+	elites := size / 10
+	if elites < 1 {
+		elites = 1
+	}
+
+	// Initialize population
+	population := make([]*Solution, size)
+	population[0] = e.greedyConstruction()
+	for i := 1; i < size; i++ {
+		population[i] = e.randomSolution()
+	}
+	e.reportPopulation(0, population)
+
+	for generation := 0; generation < e.config.MaxIterations/size; generation++ {
+		select {
+		case <-ctx.Done():
+			return e.getBestFromPopulation(population), nil
+		default:
+		}
+
+		// Replacement
+		population = e.nextGeneration(population, elites)
+		e.reportPopulation(generation+1, population)
+	}
+
+	return e.getBestFromPopulation(population), nil
+}
+
+// nextGeneration sorts the population best first and breeds one of the same
+// size. The first elites individuals are carried over unchanged.
+func (e *TimetableEngine) nextGeneration(population []*Solution, elites int) []*Solution {
+	size := len(population)
+
+	// Elitism: the best individuals survive unchanged
+	sort.SliceStable(population, func(i, j int) bool {
+		return population[i].FitnessScore > population[j].FitnessScore
+	})
+	next := make([]*Solution, 0, size)
+	next = append(next, population[:elites]...)
+
+	for len(next) < size {
+		// Selection
+		parents := e.tournamentSelection(population, 2)
+
+		// Crossover
+		child1, child2 := e.crossover(parents[0], parents[1])
+
+		// Mutation
+		for _, child := range []*Solution{child1, child2} {
+			if e.rng.Float64() < mutationRate {
+				e.mutate(child)
+			}
+		}
+
+		next = append(next, child1)
+		if len(next) < size {
+			next = append(next, child2)
+		}
+	}
+
+	return next
+}
+
+// randomSolution builds a randomised solution for the initial population
+func (e *TimetableEngine) randomSolution() *Solution {
+	return e.construct(true)
+}
+
+// tournamentSelection picks count individuals, each the fitter of two random
+// members of the population
+func (e *TimetableEngine) tournamentSelection(population []*Solution, count int) []*Solution {
+	selected := make([]*Solution, count)
+	for i := 0; i < count; i++ {
+		a := population[e.rng.Intn(len(population))]
+		b := population[e.rng.Intn(len(population))]
+		if a.FitnessScore > b.FitnessScore {
+			selected[i] = a
+		} else {
+			selected[i] = b
+		}
+	}
+	return selected
+}
+
+// crossover builds two children by taking every meeting of a course from one
+// parent or the other, course by course. The first child takes each course
+// from a random parent and the second from the other one. Taking whole
+// courses keeps each course with a single faculty member. Meetings from
+// different parents may clash, so both children are repaired.
+func (e *TimetableEngine) crossover(parent1, parent2 *Solution) (*Solution, *Solution) {
+	fromFirst := make(map[uuid.UUID]bool, len(e.courses))
+	for _, course := range e.courses {
+		fromFirst[course.ID] = e.rng.Intn(2) == 0
+	}
+
+	child1 := &Solution{Schedule: make(map[string]*ClassAssignment), Issues: parent1.Issues}
+	child2 := &Solution{Schedule: make(map[string]*ClassAssignment), Issues: parent1.Issues}
+
+	for _, key := range sortedKeys(parent1) {
+		assignment := parent1.Schedule[key]
+		if fromFirst[assignment.CourseID] {
+			child1.Schedule[key] = assignment
+		} else {
+			child2.Schedule[key] = assignment
+		}
+	}
+	for _, key := range sortedKeys(parent2) {
+		assignment := parent2.Schedule[key]
+		if fromFirst[assignment.CourseID] {
+			child2.Schedule[key] = assignment
+		} else {
+			child1.Schedule[key] = assignment
+		}
+	}
+
+	e.repair(child1)
+	e.repair(child2)
+
+	child1.FitnessScore = e.evaluateSolution(child1)
+	child2.FitnessScore = e.evaluateSolution(child2)
+	return child1, child2
+}
+
+// repair moves meetings that clash with an earlier meeting over faculty, room
+// or students to a free time and room. A meeting that fits nowhere stays where
+// it is and is left to the hard constraints.
+func (e *TimetableEngine) repair(solution *Solution) {
+	kept := &Solution{Schedule: make(map[string]*ClassAssignment, len(solution.Schedule))}
+	clashing := []string{}
+
+	for _, key := range sortedKeys(solution) {
+		assignment := solution.Schedule[key]
+		if e.isFree(kept, "", assignment.FacultyID, assignment.RoomID, assignment.CourseID, assignment) {
+			kept.Schedule[key] = assignment
+		} else {
+			clashing = append(clashing, key)
+		}
+	}
+
+	for _, key := range clashing {
+		assignment := solution.Schedule[key]
+		if relocated := e.relocate(kept, assignment); relocated != nil {
+			kept.Schedule[e.assignmentKey(relocated)] = relocated
+			continue
+		}
+		if _, taken := kept.Schedule[key]; !taken {
+			kept.Schedule[key] = assignment
+		}
+	}
+
+	solution.Schedule = kept.Schedule
+}
+
+// relocate returns a copy of the assignment at a random start slot where its
+// faculty member and students are free, in the best fitting free room, or nil
+// if there is no such place
+func (e *TimetableEngine) relocate(solution *Solution, assignment *ClassAssignment) *ClassAssignment {
+	rooms := e.suitableRooms(e.courseIndex[assignment.CourseID])
+
+	starts := e.startSlots(assignment.slotCount())
+	e.rng.Shuffle(len(starts), func(i, j int) { starts[i], starts[j] = starts[j], starts[i] })
+
+	for _, slot := range starts {
+		placed := e.placeAt(assignment, slot)
+		if placed == nil {
+			continue
+		}
+		if _, taken := solution.Schedule[e.assignmentKey(placed)]; taken {
+			continue
+		}
+		if !e.isFree(solution, "", placed.FacultyID, uuid.Nil, placed.CourseID, placed) {
+			continue
+		}
+		for _, room := range rooms {
+			if e.isFree(solution, "", uuid.Nil, room.ID, uuid.Nil, placed) {
+				placed.RoomID = room.ID
+				return placed
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *TimetableEngine) mutate(solution *Solution) {
+	if move := e.randomMove(solution); move != nil {
+		e.applyMoveIncremental(solution, move)
+	}
+}
+
+func (e *TimetableEngine) getBestFromPopulation(population []*Solution) *Solution {
+	best := population[0]
+	for _, solution := range population[1:] {
+		if solution.FitnessScore > best.FitnessScore {
+			best = solution
+		}
+	}
+	return best
+}
+
+// reportPopulation reports a generation's best solution together with how
+// diverse the population still is
+func (e *TimetableEngine) reportPopulation(generation int, population []*Solution) {
+	best := e.getBestFromPopulation(population)
+
+	progress, ok := e.progressFor(PhaseGenetic, generation, best, best)
+	if !ok {
+		return
+	}
+
+	total := 0.0
+	distinct := make(map[uint64]bool, len(population))
+	for _, solution := range population {
+		total += e.distance(solution, best)
+		distinct[scheduleSignature(solution)] = true
+	}
+
+	progress.Diversity = total / float64(len(population))
+	progress.DistinctSolutions = len(distinct)
+	e.progress(progress)
+}
+
+// distance returns the share of meetings placed differently in two solutions,
+// from 0 for identical schedules to 1 when no meeting matches
+func (e *TimetableEngine) distance(a, b *Solution) float64 {
+	if len(e.events) == 0 {
+		return 0
+	}
+
+	placements := make(map[string]*ClassAssignment, len(b.Schedule))
+	for _, assignment := range b.Schedule {
+		placements[meetingID(assignment)] = assignment
+	}
+
+	same := 0
+	for _, assignment := range a.Schedule {
+		other, ok := placements[meetingID(assignment)]
+		if ok && other.TimeSlot.ID == assignment.TimeSlot.ID && other.RoomID == assignment.RoomID && other.FacultyID == assignment.FacultyID {
+			same++
+		}
+	}
+
+	return 1 - float64(same)/float64(len(e.events))
+}
+
+// meetingID identifies which weekly meeting of its course an assignment is
+func meetingID(assignment *ClassAssignment) string {
+	return fmt.Sprintf("%s#%d", assignment.CourseID.String(), assignment.EventIndex)
+}
+
+// scheduleSignature hashes where, when and by whom every meeting is taught.
+// Solutions with the same schedule have the same signature.
+func scheduleSignature(solution *Solution) uint64 {
+	hash := fnv.New64a()
+	for _, key := range sortedKeys(solution) {
+		assignment := solution.Schedule[key]
+		hash.Write([]byte(key))
+		hash.Write(assignment.RoomID[:])
+		hash.Write(assignment.FacultyID[:])
+	}
+	return hash.Sum64()
+}
@@ -0,0 +1,188 @@
+package optimization
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// doubleBookings lists the pairs of overlapping meetings that share a faculty
+// member, a room or a course
+func doubleBookings(solution *Solution) []string {
+	keys := sortedKeys(solution)
+	clashes := []string{}
+	for i := 0; i < len(keys); i++ {
+		for j := i + 1; j < len(keys); j++ {
+			a, b := solution.Schedule[keys[i]], solution.Schedule[keys[j]]
+			if a.DayOfWeek != b.DayOfWeek || !a.overlaps(b) {
+				continue
+			}
+			if a.FacultyID == b.FacultyID || a.RoomID == b.RoomID || a.CourseID == b.CourseID {
+				clashes = append(clashes, fmt.Sprintf("%s %s and %s %s on day %d",
+					meetingID(a), a.StartTime, meetingID(b), b.StartTime, a.DayOfWeek))
+			}
+		}
+	}
+	return clashes
+}
+
+// courseFaculty returns who teaches each course, failing if a course is split
+// between faculty members or is missing a meeting
+func courseFaculty(t *testing.T, engine *TimetableEngine, solution *Solution) map[uuid.UUID]uuid.UUID {
+	t.Helper()
+
+	faculty := make(map[uuid.UUID]uuid.UUID)
+	meetings := make(map[uuid.UUID][]int)
+	for _, key := range sortedKeys(solution) {
+		assignment := solution.Schedule[key]
+		if teacher, ok := faculty[assignment.CourseID]; ok && teacher != assignment.FacultyID {
+			t.Fatalf("%s is taught by %s, other meetings of the course by %s", meetingID(assignment), assignment.FacultyID, teacher)
+		}
+		faculty[assignment.CourseID] = assignment.FacultyID
+		meetings[assignment.CourseID] = append(meetings[assignment.CourseID], assignment.EventIndex)
+	}
+
+	want := make(map[uuid.UUID][]int)
+	for _, event := range engine.events {
+		want[event.CourseID] = append(want[event.CourseID], event.Index)
+	}
+	for course, indexes := range want {
+		got := meetings[course]
+		slices.Sort(got)
+		if !slices.Equal(got, indexes) {
+			t.Fatalf("course %s has meetings %v, want %v", course, got, indexes)
+		}
+	}
+	return faculty
+}
+
+func TestCrossoverKeepsCoursesWhole(t *testing.T) {
+	engine := newTestEngine(t, newTestData(), "genetic", 9)
+	parent1 := engine.greedyConstruction()
+	parent2 := engine.randomSolution()
+	faculty1 := courseFaculty(t, engine, parent1)
+	faculty2 := courseFaculty(t, engine, parent2)
+
+	for i := 0; i < 20; i++ {
+		child1, child2 := engine.crossover(parent1, parent2)
+
+		got1 := courseFaculty(t, engine, child1)
+		got2 := courseFaculty(t, engine, child2)
+		// Each course comes whole from one parent and its sibling has the other
+		for course := range faculty1 {
+			fromFirst := got1[course] == faculty1[course] && got2[course] == faculty2[course]
+			fromSecond := got1[course] == faculty2[course] && got2[course] == faculty1[course]
+			if !fromFirst && !fromSecond {
+				t.Fatalf("crossover %d: course %s is taught by %s and %s, the parents have %s and %s",
+					i, course, got1[course], got2[course], faculty1[course], faculty2[course])
+			}
+		}
+
+		for _, child := range []*Solution{child1, child2} {
+			if clashes := doubleBookings(child); len(clashes) > 0 {
+				t.Fatalf("crossover %d: child is double booked: %v", i, clashes)
+			}
+		}
+	}
+}
+
+func TestRepairRemovesDoubleBookings(t *testing.T) {
+	engine := newTestEngine(t, newTestData(), "genetic", 4)
+	parent1 := engine.greedyConstruction()
+
+	// Even courses from one parent and odd ones from another, until the two
+	// halves clash
+	var merged *Solution
+	for attempt := 0; attempt < 20 && merged == nil; attempt++ {
+		parent2 := engine.randomSolution()
+		candidate := &Solution{Schedule: make(map[string]*ClassAssignment)}
+		for i, course := range engine.courses {
+			parent := parent1
+			if i%2 == 1 {
+				parent = parent2
+			}
+			for key, assignment := range parent.Schedule {
+				if assignment.CourseID == course.ID {
+					candidate.Schedule[key] = assignment
+				}
+			}
+		}
+		if len(doubleBookings(candidate)) > 0 {
+			merged = candidate
+		}
+	}
+	if merged == nil {
+		t.Fatal("no pair of parents clashes")
+	}
+	want := courseFaculty(t, engine, merged)
+
+	engine.repair(merged)
+
+	if clashes := doubleBookings(merged); len(clashes) > 0 {
+		t.Fatalf("repaired solution is double booked: %v", clashes)
+	}
+	// Repair moves meetings but never changes who teaches them
+	if got := courseFaculty(t, engine, merged); !maps.Equal(got, want) {
+		t.Fatalf("repair changed the faculty from %v to %v", want, got)
+	}
+	for key, assignment := range merged.Schedule {
+		if key != engine.assignmentKey(assignment) {
+			t.Errorf("%s is stored under %s", meetingID(assignment), key)
+		}
+	}
+}
+
+func TestElitesSurviveUnchanged(t *testing.T) {
+	engine := newTestEngine(t, newTestData(), "genetic", 6)
+	population := []*Solution{engine.greedyConstruction()}
+	for len(population) < 10 {
+		population = append(population, engine.randomSolution())
+	}
+	const elites = 2
+
+	for generation := 0; generation < 5; generation++ {
+		signatures := make(map[*Solution]uint64, len(population))
+		fitness := make(map[*Solution]float64, len(population))
+		for _, solution := range population {
+			signatures[solution] = scheduleSignature(solution)
+			fitness[solution] = solution.FitnessScore
+		}
+		best := slices.Clone(population)
+		slices.SortStableFunc(best, func(a, b *Solution) int {
+			switch {
+			case a.FitnessScore > b.FitnessScore:
+				return -1
+			case a.FitnessScore < b.FitnessScore:
+				return 1
+			}
+			return 0
+		})
+
+		next := engine.nextGeneration(population, elites)
+
+		if len(next) != len(best) {
+			t.Fatalf("generation %d has %d individuals, want %d", generation+1, len(next), len(best))
+		}
+		for i := 0; i < elites; i++ {
+			if next[i] != best[i] {
+				t.Fatalf("generation %d: individual %d is not the parent generation's number %d", generation+1, i, i)
+			}
+		}
+		// Breeding children must not change their parents, elites included
+		for solution, signature := range signatures {
+			if scheduleSignature(solution) != signature || solution.FitnessScore != fitness[solution] {
+				t.Fatalf("generation %d changed an individual of the one before", generation+1)
+			}
+		}
+		for _, child := range next[elites:] {
+			if _, parent := signatures[child]; parent {
+				t.Fatalf("generation %d carries over more than %d individuals", generation+1, elites)
+			}
+		}
+
+		population = next
+	}
+}
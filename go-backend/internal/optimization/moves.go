@@ -0,0 +1,414 @@
+package optimization
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Move describes a neighbourhood move as the schedule entries it removes and
+// the entries that replace them. Assignments are never mutated in place because
+// neighbouring solutions share them with their parent.
+type Move struct {
+	Operator string
+	Removed  []string
+	Added    map[string]*ClassAssignment
+}
+
+// Move operator names
+const (
+	MoveSwap          = "swap"
+	MoveRelocate      = "relocate"
+	MoveChangeRoom    = "change_room"
+	MoveChangeFaculty = "change_faculty"
+	MoveKempeChain    = "kempe_chain"
+)
+
+// moveOperator builds a move for the given solution, or returns nil when no
+// valid move of its kind exists
+type moveOperator func(solution *Solution) *Move
+
+// neighbourhood returns the move operators with their selection weights
+func (e *TimetableEngine) neighbourhood() ([]moveOperator, []float64) {
+	operators := []moveOperator{
+		e.swapRandomAssignments,
+		e.moveRandomAssignment,
+		e.changeRandomRoom,
+		e.changeRandomFaculty,
+		e.kempeChainSwap,
+	}
+	weights := []float64{0.3, 0.3, 0.15, 0.1, 0.15}
+	return operators, weights
+}
+
+// randomMove picks operators by weight until one of them yields a move
+func (e *TimetableEngine) randomMove(solution *Solution) *Move {
+	operators, weights := e.neighbourhood()
+
+	for attempt := 0; attempt < 2*len(operators); attempt++ {
+		r := e.rng.Float64()
+		chosen := len(operators) - 1
+		for i, w := range weights {
+			if r < w {
+				chosen = i
+				break
+			}
+			r -= w
+		}
+
+		if move := operators[chosen](solution); move != nil {
+			return move
+		}
+	}
+
+	return nil
+}
+
+// applyMove removes and adds the move's entries, keeping Schedule keys and the
+// occupancy index in sync with the assignments they point to. It reports false, leaving the solution
+// untouched, if an added key would overwrite an entry the move does not remove.
+func (e *TimetableEngine) applyMove(solution *Solution, move *Move) bool {
+	removed := make(map[string]bool, len(move.Removed))
+	for _, key := range move.Removed {
+		removed[key] = true
+	}
+
+	for key := range move.Added {
+		if _, exists := solution.Schedule[key]; exists && !removed[key] {
+			return false
+		}
+	}
+
+	for _, key := range move.Removed {
+		if assignment, ok := solution.Schedule[key]; ok && solution.index != nil {
+			solution.index.remove(key, assignment)
+		}
+		delete(solution.Schedule, key)
+	}
+	for key, assignment := range move.Added {
+		solution.Schedule[key] = assignment
+		if solution.index != nil {
+			solution.index.add(key, assignment)
+		}
+	}
+
+	if solution.index != nil {
+		added := make([]string, 0, len(move.Added))
+		for key := range move.Added {
+			added = append(added, key)
+		}
+		sort.Strings(added)
+		solution.index.replaceKeys(removed, added)
+	}
+
+	return true
+}
+
+// swapRandomAssignments exchanges the time slots of two assignments
+func (e *TimetableEngine) swapRandomAssignments(solution *Solution) *Move {
+	keys := sortedKeys(solution)
+	if len(keys) < 2 {
+		return nil
+	}
+
+	keyA := keys[e.rng.Intn(len(keys))]
+	keyB := keys[e.rng.Intn(len(keys))]
+	a := solution.Schedule[keyA]
+	b := solution.Schedule[keyB]
+	if keyA == keyB || a.TimeSlot.ID == b.TimeSlot.ID {
+		return nil
+	}
+
+	newA := e.placeAt(a, b.TimeSlot)
+	newB := e.placeAt(b, a.TimeSlot)
+	if newA == nil || newB == nil {
+		return nil
+	}
+
+	return &Move{
+		Operator: MoveSwap,
+		Removed:  []string{keyA, keyB},
+		Added: map[string]*ClassAssignment{
+			e.assignmentKey(newA): newA,
+			e.assignmentKey(newB): newB,
+		},
+	}
+}
+
+// moveRandomAssignment relocates one assignment to a slot where its faculty,
+// room and students are all free
+func (e *TimetableEngine) moveRandomAssignment(solution *Solution) *Move {
+	keys := sortedKeys(solution)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	key := keys[e.rng.Intn(len(keys))]
+	assignment := solution.Schedule[key]
+
+	candidates := []*ClassAssignment{}
+	for _, slot := range e.startSlots(assignment.Length) {
+		if slot.ID == assignment.TimeSlot.ID {
+			continue
+		}
+		placed := e.placeAt(assignment, slot)
+		if placed == nil || !e.isFree(solution, key, assignment.FacultyID, assignment.RoomID, assignment.CourseID, placed) {
+			continue
+		}
+		candidates = append(candidates, placed)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	moved := candidates[e.rng.Intn(len(candidates))]
+
+	return &Move{
+		Operator: MoveRelocate,
+		Removed:  []string{key},
+		Added:    map[string]*ClassAssignment{e.assignmentKey(moved): moved},
+	}
+}
+
+// changeRandomRoom moves one assignment to another suitable room that is free
+// at the same time
+func (e *TimetableEngine) changeRandomRoom(solution *Solution) *Move {
+	keys := sortedKeys(solution)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	key := keys[e.rng.Intn(len(keys))]
+	assignment := solution.Schedule[key]
+	course, ok := e.courseIndex[assignment.CourseID]
+	if !ok {
+		return nil
+	}
+
+	candidates := []uuid.UUID{}
+	for _, room := range e.suitableRooms(course) {
+		if room.ID == assignment.RoomID {
+			continue
+		}
+		if !e.isFree(solution, key, uuid.Nil, room.ID, uuid.Nil, assignment) {
+			continue
+		}
+		candidates = append(candidates, room.ID)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	changed := *assignment
+	changed.RoomID = candidates[e.rng.Intn(len(candidates))]
+
+	return &Move{
+		Operator: MoveChangeRoom,
+		Removed:  []string{key},
+		Added:    map[string]*ClassAssignment{key: &changed},
+	}
+}
+
+// changeRandomFaculty hands every meeting of a random course to another
+// qualified faculty member who is free at all of them, so each course keeps a
+// single faculty member
+func (e *TimetableEngine) changeRandomFaculty(solution *Solution) *Move {
+	keys := sortedKeys(solution)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	picked := solution.Schedule[keys[e.rng.Intn(len(keys))]]
+	meetings := []string{}
+	for _, key := range keys {
+		if solution.Schedule[key].CourseID == picked.CourseID {
+			meetings = append(meetings, key)
+		}
+	}
+
+	candidates := []uuid.UUID{}
+	for _, faculty := range e.qualifiedFaculty(picked.CourseID) {
+		if faculty.ID == picked.FacultyID {
+			continue
+		}
+		free := true
+		for _, key := range meetings {
+			if !e.isFree(solution, key, faculty.ID, uuid.Nil, uuid.Nil, solution.Schedule[key]) {
+				free = false
+				break
+			}
+		}
+		if free {
+			candidates = append(candidates, faculty.ID)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	facultyID := candidates[e.rng.Intn(len(candidates))]
+	move := &Move{
+		Operator: MoveChangeFaculty,
+		Removed:  meetings,
+		Added:    make(map[string]*ClassAssignment, len(meetings)),
+	}
+	for _, key := range meetings {
+		changed := *solution.Schedule[key]
+		changed.FacultyID = facultyID
+		move.Added[key] = &changed
+	}
+
+	return move
+}
+
+// kempeChainSwap picks an assignment and another window of the same length,
+// then grows the chain of assignments in the two windows that are linked by a
+// shared faculty member, room or students. Swapping the whole chain between
+// the windows cannot create a clash that was not already there. Chains that
+// reach a meeting not aligned with the windows are abandoned.
+func (e *TimetableEngine) kempeChainSwap(solution *Solution) *Move {
+	keys := sortedKeys(solution)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	seedKey := keys[e.rng.Intn(len(keys))]
+	seed := solution.Schedule[seedKey]
+	starts := e.startSlots(seed.Length)
+	if len(starts) < 2 {
+		return nil
+	}
+
+	windowA := seed
+	windowB := e.placeAt(seed, starts[e.rng.Intn(len(starts))])
+	if windowB == nil || windowB.TimeSlot.ID == windowA.TimeSlot.ID || windowB.overlaps(windowA) {
+		return nil
+	}
+
+	// Assignments touching either window
+	inWindows := []string{}
+	for _, key := range keys {
+		assignment := solution.Schedule[key]
+		if assignment.overlaps(windowA) || assignment.overlaps(windowB) {
+			inWindows = append(inWindows, key)
+		}
+	}
+
+	chain := map[string]bool{seedKey: true}
+	queue := []string{seedKey}
+	for len(queue) > 0 {
+		current := solution.Schedule[queue[0]]
+		queue = queue[1:]
+
+		for _, key := range inWindows {
+			if chain[key] {
+				continue
+			}
+			other := solution.Schedule[key]
+			if other.overlaps(current) {
+				continue
+			}
+			if !e.linked(other, current) {
+				continue
+			}
+			aligned := other.Length == seed.Length &&
+				(other.TimeSlot.ID == windowA.TimeSlot.ID || other.TimeSlot.ID == windowB.TimeSlot.ID)
+			if !aligned {
+				return nil
+			}
+			chain[key] = true
+			queue = append(queue, key)
+		}
+	}
+
+	move := &Move{
+		Operator: MoveKempeChain,
+		Removed:  make([]string, 0, len(chain)),
+		Added:    make(map[string]*ClassAssignment, len(chain)),
+	}
+	for _, key := range inWindows {
+		if !chain[key] {
+			continue
+		}
+		assignment := solution.Schedule[key]
+		target := windowB.TimeSlot
+		if assignment.TimeSlot.ID == windowB.TimeSlot.ID {
+			target = windowA.TimeSlot
+		}
+		swapped := e.placeAt(assignment, target)
+		if swapped == nil {
+			return nil
+		}
+		move.Removed = append(move.Removed, key)
+		move.Added[e.assignmentKey(swapped)] = swapped
+	}
+
+	return move
+}
+
+// isFree reports whether the given faculty member, room and the students of
+// the given course are all unused during the placed assignment's time,
+// ignoring the assignment stored under ignoreKey. A nil ID skips that check.
+func (e *TimetableEngine) isFree(solution *Solution, ignoreKey string, facultyID, roomID, courseID uuid.UUID, placed *ClassAssignment) bool {
+	// Only meetings on the same day can overlap
+	if solution.index != nil {
+		for _, key := range solution.index.byDay[placed.DayOfWeek] {
+			if !e.fitsWith(solution.Schedule[key], key, ignoreKey, facultyID, roomID, courseID, placed) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for key, assignment := range solution.Schedule {
+		if !e.fitsWith(assignment, key, ignoreKey, facultyID, roomID, courseID, placed) {
+			return false
+		}
+	}
+	return true
+}
+
+// fitsWith reports whether an existing assignment leaves room for the placed
+// one, as checked by isFree
+func (e *TimetableEngine) fitsWith(assignment *ClassAssignment, key, ignoreKey string, facultyID, roomID, courseID uuid.UUID, placed *ClassAssignment) bool {
+	if key == ignoreKey || !assignment.overlaps(placed) {
+		return true
+	}
+	if facultyID != uuid.Nil && assignment.FacultyID == facultyID {
+		return false
+	}
+	if roomID != uuid.Nil && assignment.RoomID == roomID {
+		return false
+	}
+	if courseID != uuid.Nil && (assignment.CourseID == courseID || e.conflicts.Shared(assignment.CourseID, courseID) > 0) {
+		return false
+	}
+	return true
+}
+
+// linked reports whether two assignments compete for the same faculty
+// member, room or students
+func (e *TimetableEngine) linked(a, b *ClassAssignment) bool {
+	return a.FacultyID == b.FacultyID || a.RoomID == b.RoomID || e.conflicts.Shared(a.CourseID, b.CourseID) > 0
+}
+
+// assignmentKey builds the Schedule key an assignment must be stored under
+func (e *TimetableEngine) assignmentKey(assignment *ClassAssignment) string {
+	return e.makeKey(assignment.CourseID, assignment.DayOfWeek, assignment.TimeSlot.ID)
+}
+
+// sortedKeys returns the Schedule keys in a stable order so that random picks
+// do not depend on map iteration order. A solution with an occupancy index
+// already has them sorted; the returned slice must not be modified.
+func sortedKeys(solution *Solution) []string {
+	if solution.index != nil {
+		return solution.index.keys
+	}
+
+	keys := make([]string, 0, len(solution.Schedule))
+	for key := range solution.Schedule {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package optimization
+
+import "time"
+
+// Search phases reported through progress updates
+const (
+	PhaseGreedy             = "greedy"
+	PhaseSimulatedAnnealing = "simulated_annealing"
+	PhaseTabuSearch         = "tabu_search"
+	PhaseHillClimbing       = "hill_climbing"
+	PhaseGenetic            = "genetic"
+)
+
+// Progress is a snapshot of a running search
+type Progress struct {
+	Phase          string  `json:"phase"`
+	Iteration      int     `json:"iteration"`
+	CurrentFitness float64 `json:"current_fitness"`
+	BestFitness    float64 `json:"best_fitness"`
+	HardViolations int     `json:"hard_violations"` // of the best solution
+	SoftViolations int     `json:"soft_violations"` // of the best solution
+	ElapsedMs      int64   `json:"elapsed_ms"`
+
+	// Genetic algorithm only: mean share of meetings placed differently from
+	// the best individual, and the number of distinct schedules
+	Diversity         float64 `json:"diversity,omitempty"`
+	DistinctSolutions int     `json:"distinct_solutions,omitempty"`
+}
+
+// ProgressFunc receives progress updates while the engine runs. Every
+// algorithm reports after each iteration and when it changes phase. It is
+// called from the search loop, so it should return quickly.
+type ProgressFunc func(Progress)
+
+// Iterations returns how many search steps all workers have taken so far
+func (e *TimetableEngine) Iterations() int64 {
+	return e.iterations.Load()
+}
+
+// OnProgress registers a function to be told about the search as it runs
+func (e *TimetableEngine) OnProgress(fn ProgressFunc) {
+	e.progress = fn
+}
+
+// report shares a worker's best solution with the other workers and sends a
+// progress update if anyone is listening
+func (e *TimetableEngine) report(phase string, iteration int, current, best *Solution) {
+	if progress, ok := e.progressFor(phase, iteration, current, best); ok {
+		e.progress(progress)
+	}
+}
+
+// progressFor offers best to the other workers and builds the update to send.
+// ok is false when nobody is listening. Only the first worker sends updates,
+// so phases arrive in order, but the best fitness is the best of all workers.
+func (e *TimetableEngine) progressFor(phase string, iteration int, current, best *Solution) (Progress, bool) {
+	counter := e
+	if e.shared != nil {
+		best = e.shared.offer(best)
+		counter = e.shared
+	}
+	if iteration > 0 {
+		counter.iterations.Add(1)
+	}
+	if e.progress == nil || e.worker != 0 {
+		return Progress{}, false
+	}
+
+	return Progress{
+		Phase:          phase,
+		Iteration:      iteration,
+		CurrentFitness: current.FitnessScore,
+		BestFitness:    best.FitnessScore,
+		HardViolations: best.HardViolations,
+		SoftViolations: best.SoftViolations,
+		ElapsedMs:      time.Since(e.startedAt).Milliseconds(),
+	}, true
+}
@@ -0,0 +1,446 @@
+package optimization
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+// Constraint types, as stored in timetable_constraints.constraint_type
+const (
+	ConstraintNoFacultyDoubleBooking    = "NO_FACULTY_DOUBLE_BOOKING"
+	ConstraintNoRoomDoubleBooking       = "NO_ROOM_DOUBLE_BOOKING"
+	ConstraintStudentGroupClash         = "STUDENT_GROUP_CLASH"
+	ConstraintFacultyWorkloadLimit      = "FACULTY_WORKLOAD_LIMIT"
+	ConstraintRoomCapacity              = "ROOM_CAPACITY"
+	ConstraintLabRoomRequirement        = "LAB_ROOM_REQUIREMENT"
+	ConstraintFacultyAvailability       = "FACULTY_AVAILABILITY"
+	ConstraintPreferMorningTheory       = "PREFER_MORNING_THEORY"
+	ConstraintFacultyPreference         = "FACULTY_PREFERENCE"
+	ConstraintNoBackToBackLabs          = "NO_BACK_TO_BACK_LABS"
+	ConstraintBalancedDailyDistribution = "BALANCED_DAILY_DISTRIBUTION"
+)
+
+// Constraint parameter value types
+const (
+	ParamInteger = "integer"
+	ParamTime    = "time"
+)
+
+// ConstraintInput is the scheduling data constraints are built from
+type ConstraintInput struct {
+	Courses     []models.Course
+	Faculty     []models.Faculty
+	Rooms       []models.Room
+	Enrollments []models.StudentEnrollment
+	Conflicts   *CourseConflictGraph
+}
+
+// ConstraintParam describes one field of a constraint's ConstraintData
+type ConstraintParam struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Required    bool        `json:"required"`
+	Default     interface{} `json:"default,omitempty"`
+	Minimum     *int        `json:"minimum,omitempty"`
+	Maximum     *int        `json:"maximum,omitempty"`
+	Description string      `json:"description"`
+}
+
+// ConstraintType is a registered kind of constraint that timetables can
+// configure through TimetableConstraint rows
+type ConstraintType struct {
+	Type        string            `json:"type"`
+	Description string            `json:"description"`
+	Hard        bool              `json:"hard"`     // hard unless configured otherwise
+	Required    bool              `json:"required"` // always enforced as a hard constraint
+	Default     bool              `json:"default"`  // enforced unless a timetable's row disables it
+	Params      []ConstraintParam `json:"params"`
+
+	build func(input *ConstraintInput, data map[string]interface{}) Constraint
+}
+
+// Name is the name the constraint is added to the engine under
+func (t *ConstraintType) Name() string {
+	return strings.ToLower(t.Type)
+}
+
+// Validate checks constraint data against the type's parameters. Unknown
+// fields, values of the wrong type and missing required values are errors.
+func (t *ConstraintType) Validate(data map[string]interface{}) error {
+	known := make(map[string]bool, len(t.Params))
+	for _, param := range t.Params {
+		known[param.Name] = true
+
+		value, ok := data[param.Name]
+		if !ok || value == nil {
+			if param.Required {
+				return fmt.Errorf("%s is required", param.Name)
+			}
+			continue
+		}
+		if err := param.validate(value); err != nil {
+			return err
+		}
+	}
+
+	for name := range data {
+		if !known[name] {
+			return fmt.Errorf("unknown parameter %s for %s", name, t.Type)
+		}
+	}
+
+	return nil
+}
+
+func (p *ConstraintParam) validate(value interface{}) error {
+	switch p.Type {
+	case ParamInteger:
+		// JSON numbers decode as float64
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return fmt.Errorf("%s must be an integer", p.Name)
+		}
+		if p.Minimum != nil && int(number) < *p.Minimum {
+			return fmt.Errorf("%s must be at least %d", p.Name, *p.Minimum)
+		}
+		if p.Maximum != nil && int(number) > *p.Maximum {
+			return fmt.Errorf("%s must be at most %d", p.Name, *p.Maximum)
+		}
+	case ParamTime:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a time of day", p.Name)
+		}
+		if _, err := ParseClock(text); err != nil {
+			return fmt.Errorf("%s must be a time of day: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// Build validates the data and creates the constraint. Parameters missing
+// from data take their defaults.
+func (t *ConstraintType) Build(input *ConstraintInput, data map[string]interface{}) (Constraint, error) {
+	if err := t.Validate(data); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(t.Params))
+	for _, param := range t.Params {
+		if value, ok := data[param.Name]; ok && value != nil {
+			values[param.Name] = value
+		} else if param.Default != nil {
+			values[param.Name] = param.Default
+		}
+	}
+
+	return t.build(input, values), nil
+}
+
+var constraintRegistry = map[string]*ConstraintType{}
+
+// registerConstraint adds a constraint type to the registry, taking its
+// description from the constraint itself and noting when it applies without
+// being configured
+func registerConstraint(t *ConstraintType) {
+	t.Description = t.build(&ConstraintInput{}, map[string]interface{}{}).GetDescription()
+	switch {
+	case t.Required:
+		t.Description += " (always enforced)"
+	case t.Default:
+		t.Description += " (enforced on every timetable unless a constraint row sets is_disabled)"
+	}
+	if t.Params == nil {
+		t.Params = []ConstraintParam{}
+	}
+	constraintRegistry[t.Type] = t
+}
+
+// LookupConstraintType returns the registered constraint type with the given name
+func LookupConstraintType(name string) (*ConstraintType, bool) {
+	t, ok := constraintRegistry[name]
+	return t, ok
+}
+
+// ConstraintTypes lists every registered constraint type, sorted by name
+func ConstraintTypes() []*ConstraintType {
+	types := make([]*ConstraintType, 0, len(constraintRegistry))
+	for _, t := range constraintRegistry {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	return types
+}
+
+// LoadConstraints adds the constraints configured for a timetable. Each row
+// is built by its registered type; a soft constraint's Priority becomes its
+// penalty weight. Default constraints no row configures are added with their
+// own hardness unless a row disables them; required constraints cannot be
+// disabled and are always enforced as hard ones.
+func (e *TimetableEngine) LoadConstraints(input *ConstraintInput, rows []models.TimetableConstraint) error {
+	configured := make(map[string]bool)
+
+	for _, row := range rows {
+		t, ok := LookupConstraintType(row.ConstraintType)
+		if !ok {
+			return fmt.Errorf("unknown constraint type %s", row.ConstraintType)
+		}
+		if configured[t.Type] {
+			return fmt.Errorf("constraint type %s is configured more than once", t.Type)
+		}
+		configured[t.Type] = true
+
+		if row.IsDisabled && !t.Required {
+			continue
+		}
+
+		constraint, err := t.Build(input, row.ConstraintData)
+		if err != nil {
+			return fmt.Errorf("invalid %s constraint: %w", t.Type, err)
+		}
+
+		weight := float64(row.Priority)
+		if weight < 1 {
+			weight = 1
+		}
+		e.AddWeightedConstraint(t.Name(), constraint, row.IsHardConstraint || t.Required, weight)
+	}
+
+	for _, t := range ConstraintTypes() {
+		if configured[t.Type] || !(t.Required || t.Default) {
+			continue
+		}
+		constraint, err := t.Build(input, nil)
+		if err != nil {
+			return fmt.Errorf("invalid %s constraint: %w", t.Type, err)
+		}
+		e.AddWeightedConstraint(t.Name(), constraint, t.Hard, 1)
+	}
+
+	return nil
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func init() {
+	registerConstraint(&ConstraintType{
+		Type:     ConstraintNoFacultyDoubleBooking,
+		Hard:     true,
+		Required: true,
+		Default:  true,
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			return &NoFacultyDoubleBooking{}
+		},
+	})
+
+	registerConstraint(&ConstraintType{
+		Type:     ConstraintNoRoomDoubleBooking,
+		Hard:     true,
+		Required: true,
+		Default:  true,
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			return &NoRoomDoubleBooking{}
+		},
+	})
+
+	registerConstraint(&ConstraintType{
+		Type:     ConstraintStudentGroupClash,
+		Hard:     true,
+		Required: true,
+		Default:  true,
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			return &StudentGroupClash{Conflicts: input.Conflicts}
+		},
+	})
+
+	registerConstraint(&ConstraintType{
+		Type:    ConstraintFacultyWorkloadLimit,
+		Hard:    true,
+		Default: true,
+		Params: []ConstraintParam{{
+			Name:        "max_hours",
+			Type:        ParamInteger,
+			Minimum:     intPtr(1),
+			Maximum:     intPtr(168),
+			Description: "Weekly teaching hours no faculty member may exceed; lower personal limits still apply",
+		}},
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			maxHours := facultyMaxHours(input.Faculty)
+			if limit, ok := data["max_hours"].(float64); ok {
+				for id, hours := range maxHours {
+					if hours > int(limit) {
+						maxHours[id] = int(limit)
+					}
+				}
+			}
+			return &FacultyWorkloadLimit{MaxHours: maxHours}
+		},
+	})
+
+	registerConstraint(&ConstraintType{
+		Type:    ConstraintRoomCapacity,
+		Hard:    true,
+		Default: true,
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			return &RoomCapacityConstraint{
+				RoomCapacities:    roomCapacities(input.Rooms),
+				CourseEnrollments: courseEnrollmentCounts(input.Enrollments),
+			}
+		},
+	})
+
+	registerConstraint(&ConstraintType{
+		Type: ConstraintLabRoomRequirement,
+		Hard: true,
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			return &LabRoomRequirement{
+				LabCourses: courseIDsOfType(input.Courses, "LAB"),
+				LabRooms:   roomIDsOfType(input.Rooms, "LAB"),
+			}
+		},
+	})
+
+	registerConstraint(&ConstraintType{
+		Type: ConstraintFacultyAvailability,
+		Hard: true,
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			return &FacultyAvailability{Availability: facultyAvailability(input.Faculty)}
+		},
+	})
+
+	registerConstraint(&ConstraintType{
+		Type:    ConstraintPreferMorningTheory,
+		Default: true,
+		Params: []ConstraintParam{
+			{
+				Name:        "afternoon_start",
+				Type:        ParamTime,
+				Default:     "12:00",
+				Description: "Theory classes starting after this time are penalised",
+			},
+			{
+				Name:        "evening_start",
+				Type:        ParamTime,
+				Default:     "15:00",
+				Description: "Theory classes starting after this time are penalised more",
+			},
+		},
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			afternoon, _ := data["afternoon_start"].(string)
+			evening, _ := data["evening_start"].(string)
+			return &PreferMorningForTheory{
+				TheoryCourses:  courseIDsOfType(input.Courses, "THEORY"),
+				AfternoonStart: afternoon,
+				EveningStart:   evening,
+			}
+		},
+	})
+
+	registerConstraint(&ConstraintType{
+		Type:    ConstraintFacultyPreference,
+		Default: true,
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			return &FacultyPreference{Preferences: facultyPreferences(input.Faculty)}
+		},
+	})
+
+	registerConstraint(&ConstraintType{
+		Type: ConstraintNoBackToBackLabs,
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			return &AvoidBackToBackLabs{LabCourses: courseIDsOfType(input.Courses, "LAB")}
+		},
+	})
+
+	registerConstraint(&ConstraintType{
+		Type: ConstraintBalancedDailyDistribution,
+		build: func(input *ConstraintInput, data map[string]interface{}) Constraint {
+			return &BalancedDailyDistribution{}
+		},
+	})
+}
+
+func courseIDsOfType(courses []models.Course, courseType string) []string {
+	ids := []string{}
+	for _, course := range courses {
+		if course.CourseType == courseType {
+			ids = append(ids, course.ID.String())
+		}
+	}
+	return ids
+}
+
+func roomIDsOfType(rooms []models.Room, roomType string) []string {
+	ids := []string{}
+	for _, room := range rooms {
+		if room.RoomType == roomType {
+			ids = append(ids, room.ID.String())
+		}
+	}
+	return ids
+}
+
+func roomCapacities(rooms []models.Room) map[string]int {
+	capacities := make(map[string]int)
+	for _, room := range rooms {
+		capacities[room.ID.String()] = room.Capacity
+	}
+	return capacities
+}
+
+func courseEnrollmentCounts(enrollments []models.StudentEnrollment) map[string]int {
+	counts := make(map[string]int)
+	for _, enrollment := range enrollments {
+		if enrollment.Status == "DROPPED" {
+			continue
+		}
+		counts[enrollment.CourseID.String()]++
+	}
+	return counts
+}
+
+func facultyMaxHours(faculty []models.Faculty) map[string]int {
+	maxHours := make(map[string]int)
+	for _, member := range faculty {
+		maxHours[member.ID.String()] = member.MaxHoursPerWeek
+	}
+	return maxHours
+}
+
+func facultyPreferences(faculty []models.Faculty) map[string]map[string]int {
+	preferences := make(map[string]map[string]int)
+	for _, member := range faculty {
+		courses := make(map[string]int)
+		for _, expertise := range member.CourseExpertise {
+			courses[expertise.CourseID.String()] = expertise.PreferenceLevel
+		}
+		preferences[member.ID.String()] = courses
+	}
+	return preferences
+}
+
+// facultyAvailability collects the available time ranges of each faculty
+// member by day. Members without any recorded availability are left out and
+// so count as always available.
+func facultyAvailability(faculty []models.Faculty) map[string]map[int][]TimeRange {
+	availability := make(map[string]map[int][]TimeRange)
+	for _, member := range faculty {
+		if len(member.Availability) == 0 {
+			continue
+		}
+		days := make(map[int][]TimeRange)
+		for _, slot := range member.Availability {
+			if !slot.IsAvailable {
+				continue
+			}
+			days[slot.DayOfWeek] = append(days[slot.DayOfWeek], TimeRange{Start: slot.StartTime, End: slot.EndTime})
+		}
+		availability[member.ID.String()] = days
+	}
+	return availability
+}
@@ -0,0 +1,87 @@
+package optimization
+
+import (
+	"sort"
+
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+// DefaultRoomCompatibility maps each course type to the room types it may use,
+// in order of preference. Later entries are fallbacks for when no room of an
+// earlier type fits.
+var DefaultRoomCompatibility = map[string][]string{
+	"THEORY":    {"CLASSROOM", "SEMINAR_HALL", "AUDITORIUM"},
+	"LAB":       {"LAB"},
+	"PRACTICAL": {"LAB", "CLASSROOM"},
+	"SEMINAR":   {"SEMINAR_HALL", "CONFERENCE_ROOM", "CLASSROOM"},
+	"PROJECT":   {"LAB", "CONFERENCE_ROOM", "CLASSROOM"},
+	"FIELDWORK": {"CLASSROOM", "SEMINAR_HALL"},
+}
+
+// roomCompatibility returns the configured compatibility table, falling back
+// to DefaultRoomCompatibility for course types the config does not mention
+func (e *TimetableEngine) roomCompatibility(courseType string) []string {
+	if roomTypes, ok := e.config.RoomCompatibility[courseType]; ok {
+		return roomTypes
+	}
+	return DefaultRoomCompatibility[courseType]
+}
+
+// hasEquipment reports whether the room provides everything the course needs
+func hasEquipment(room *models.Room, course models.Course) bool {
+	if course.RequiresProjector && !room.HasProjector {
+		return false
+	}
+	if course.RequiresComputer && !room.HasComputer {
+		return false
+	}
+	if course.RequiresSmartBoard && !room.HasSmartBoard {
+		return false
+	}
+	if course.RequiresAC && !room.IsAC {
+		return false
+	}
+	return true
+}
+
+// suitableRooms returns the rooms a course may use, best fit first. A room
+// qualifies when its type is compatible with the course type, it seats every
+// enrolled student and it has the equipment the course requires. Rooms are
+// ranked by the preference order of their type and then by the smallest
+// spare capacity, so small classes stay out of large halls.
+func (e *TimetableEngine) suitableRooms(course models.Course) []*models.Room {
+	rank := make(map[string]int)
+	for i, roomType := range e.roomCompatibility(course.CourseType) {
+		rank[roomType] = i
+	}
+
+	enrolled := e.enrollments[course.ID]
+
+	rooms := []*models.Room{}
+	for i := range e.rooms {
+		room := &e.rooms[i]
+		if _, ok := rank[room.RoomType]; !ok {
+			continue
+		}
+		if room.Capacity < enrolled {
+			continue
+		}
+		if !hasEquipment(room, course) {
+			continue
+		}
+		rooms = append(rooms, room)
+	}
+
+	sort.SliceStable(rooms, func(i, j int) bool {
+		a, b := rooms[i], rooms[j]
+		if rank[a.RoomType] != rank[b.RoomType] {
+			return rank[a.RoomType] < rank[b.RoomType]
+		}
+		if a.Capacity != b.Capacity {
+			return a.Capacity < b.Capacity
+		}
+		return a.RoomNumber < b.RoomNumber
+	})
+
+	return rooms
+}
@@ -0,0 +1,122 @@
+package optimization
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestSuitableRooms(t *testing.T) {
+	// Listed out of order on purpose
+	rooms := []models.Room{
+		{RoomNumber: "AUD", RoomType: "AUDITORIUM", Capacity: 300},
+		{RoomNumber: "CR1", RoomType: "CONFERENCE_ROOM", Capacity: 12},
+		{RoomNumber: "L2", RoomType: "LAB", Capacity: 25, HasComputer: true},
+		{RoomNumber: "L1", RoomType: "LAB", Capacity: 30, HasComputer: true},
+		{RoomNumber: "SH1", RoomType: "SEMINAR_HALL", Capacity: 40},
+		{RoomNumber: "301", RoomType: "CLASSROOM", Capacity: 80},
+		{RoomNumber: "201", RoomType: "CLASSROOM", Capacity: 50, HasProjector: true, IsAC: true},
+		{RoomNumber: "102", RoomType: "CLASSROOM", Capacity: 30},
+		{RoomNumber: "101", RoomType: "CLASSROOM", Capacity: 30, HasProjector: true},
+	}
+	for i := range rooms {
+		rooms[i].ID = testID("room" + rooms[i].RoomNumber)
+		rooms[i].IsAvailable = true
+	}
+
+	tests := []struct {
+		name          string
+		course        models.Course
+		enrolled      int
+		compatibility map[string][]string
+		want          []string
+	}{
+		{
+			name:   "preferred type first, then smallest",
+			course: models.Course{CourseType: "THEORY"},
+			want:   []string{"101", "102", "201", "301", "SH1", "AUD"},
+		},
+		{
+			name:     "rooms too small for the enrolment are left out",
+			course:   models.Course{CourseType: "THEORY"},
+			enrolled: 45,
+			want:     []string{"201", "301", "AUD"},
+		},
+		{
+			name:     "a fallback type when no preferred room fits",
+			course:   models.Course{CourseType: "THEORY"},
+			enrolled: 100,
+			want:     []string{"AUD"},
+		},
+		{
+			name:   "required equipment",
+			course: models.Course{CourseType: "THEORY", RequiresProjector: true},
+			want:   []string{"101", "201"},
+		},
+		{
+			name:     "all required equipment and capacity",
+			course:   models.Course{CourseType: "THEORY", RequiresProjector: true, RequiresAC: true},
+			enrolled: 45,
+			want:     []string{"201"},
+		},
+		{
+			name:     "labs only",
+			course:   models.Course{CourseType: "LAB", RequiresComputer: true},
+			enrolled: 28,
+			want:     []string{"L1"},
+		},
+		{
+			name:     "practicals fall back to classrooms",
+			course:   models.Course{CourseType: "PRACTICAL"},
+			enrolled: 28,
+			want:     []string{"L1", "101", "102", "201", "301"},
+		},
+		{
+			name:          "configured compatibility replaces the default",
+			course:        models.Course{CourseType: "SEMINAR"},
+			compatibility: map[string][]string{"SEMINAR": {"CONFERENCE_ROOM", "AUDITORIUM"}},
+			want:          []string{"CR1", "AUD"},
+		},
+		{
+			name:          "types the configuration omits keep the default",
+			course:        models.Course{CourseType: "THEORY"},
+			enrolled:      60,
+			compatibility: map[string][]string{"SEMINAR": {"CONFERENCE_ROOM"}},
+			want:          []string{"301", "AUD"},
+		},
+		{
+			name:   "unknown course type",
+			course: models.Course{CourseType: "WORKSHOP"},
+			want:   []string{},
+		},
+		{
+			name:     "nothing is large enough",
+			course:   models.Course{CourseType: "THEORY"},
+			enrolled: 400,
+			want:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.course.ID = testID("course")
+			engine := NewTimetableEngine(testID("timetable"), &EngineConfig{RoomCompatibility: tt.compatibility})
+			engine.LoadData([]models.Course{tt.course}, nil, rooms, nil)
+
+			enrollments := make([]models.StudentEnrollment, tt.enrolled)
+			for i := range enrollments {
+				enrollments[i].CourseID = tt.course.ID
+			}
+			engine.LoadEnrollments(enrollments)
+
+			got := []string{}
+			for _, room := range engine.suitableRooms(tt.course) {
+				got = append(got, room.RoomNumber)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("suitable rooms are %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
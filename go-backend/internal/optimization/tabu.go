@@ -0,0 +1,141 @@
+package optimization
+
+import (
+	"context"
+	"fmt"
+)
+
+// defaultTabuTenure is used when EngineConfig.TabuTenure is not set
+const defaultTabuTenure = 10
+
+// tabuList remembers the placements that recent moves took meetings out of.
+// A move that would put a meeting straight back into one of them is tabu
+// until its tenure runs out, so the search cannot simply undo itself.
+type tabuList struct {
+	tenure  int
+	expires map[string]int // placement -> first iteration it is allowed again
+}
+
+func newTabuList(tenure int) *tabuList {
+	if tenure <= 0 {
+		tenure = defaultTabuTenure
+	}
+	return &tabuList{tenure: tenure, expires: make(map[string]int)}
+}
+
+// placement identifies a meeting together with its time, room and faculty
+func placement(assignment *ClassAssignment) string {
+	return fmt.Sprintf("%s@%s/%s/%s", meetingID(assignment), assignment.TimeSlot.ID, assignment.RoomID, assignment.FacultyID)
+}
+
+// isTabu reports whether the move restores a placement left recently
+func (t *tabuList) isTabu(move *Move, iteration int) bool {
+	for _, assignment := range move.Added {
+		if iteration < t.expires[placement(assignment)] {
+			return true
+		}
+	}
+	return false
+}
+
+// forbidReturn makes the placements removed by a move applied to from tabu
+func (t *tabuList) forbidReturn(from *Solution, move *Move, iteration int) {
+	for _, key := range move.Removed {
+		if assignment, ok := from.Schedule[key]; ok {
+			t.expires[placement(assignment)] = iteration + t.tenure + 1
+		}
+	}
+
+	// Clean old tabu entries
+	for k, v := range t.expires {
+		if v <= iteration {
+			delete(t.expires, k)
+		}
+	}
+}
+
+// tabuStep samples count neighbours of current and returns the best one the
+// tabu list allows, together with its move. A tabu neighbour is still allowed
+// when it beats the best solution found so far (aspiration).
+func (e *TimetableEngine) tabuStep(current, best *Solution, tabu *tabuList, iteration, count int) (*Solution, *Move) {
+	var bestNeighbor *Solution
+	var bestMove *Move
+
+	for i := 0; i < count; i++ {
+		neighbor, move := e.generateNeighborMove(current)
+		if move == nil {
+			continue
+		}
+		if tabu.isTabu(move, iteration) && neighbor.FitnessScore <= best.FitnessScore {
+			continue
+		}
+
+		if bestNeighbor == nil || neighbor.FitnessScore > bestNeighbor.FitnessScore {
+			bestNeighbor, bestMove = neighbor, move
+		}
+	}
+
+	return bestNeighbor, bestMove
+}
+
+// tabuSearch implements tabu search optimization
+func (e *TimetableEngine) tabuSearch(ctx context.Context) (*Solution, error) {
+	current := e.greedyConstruction()
+	best := current
+	tabu := newTabuList(e.config.TabuTenure)
+	e.report(PhaseGreedy, 0, current, best)
+
+	for i := 0; i < e.config.MaxIterations; i++ {
+		select {
+		case <-ctx.Done():
+			return best, nil
+		default:
+		}
+
+		// Generate neighbors and select best non-tabu
+		bestNeighbor, move := e.tabuStep(current, best, tabu, i, 20)
+		if bestNeighbor == nil {
+			break
+		}
+
+		// Update tabu list
+		tabu.forbidReturn(current, move, i)
+
+		current = bestNeighbor
+		if current.FitnessScore > best.FitnessScore {
+			best = current
+		}
+		e.report(PhaseTabuSearch, i+1, current, best)
+	}
+
+	return best, nil
+}
+
+// improveWithTabuSearch improves a solution using tabu search
+func (e *TimetableEngine) improveWithTabuSearch(ctx context.Context, initial *Solution, iterations int) *Solution {
+	current := initial
+	best := initial
+	tabu := newTabuList(e.config.TabuTenure)
+	e.report(PhaseTabuSearch, 0, current, best)
+
+	for i := 0; i < iterations; i++ {
+		select {
+		case <-ctx.Done():
+			return best
+		default:
+		}
+
+		bestNeighbor, move := e.tabuStep(current, best, tabu, i, 10)
+
+		if bestNeighbor != nil {
+			tabu.forbidReturn(current, move, i)
+			current = bestNeighbor
+			if current.FitnessScore > best.FitnessScore {
+				best = current
+			}
+		}
+		e.report(PhaseTabuSearch, i+1, current, best)
+	}
+
+	return best
+}
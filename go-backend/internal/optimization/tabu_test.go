@@ -0,0 +1,108 @@
+package optimization
+
+import (
+	"testing"
+
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+func TestTabuListExpiresAfterTenure(t *testing.T) {
+	var slot, otherSlot models.TimeSlot
+	slot.ID, otherSlot.ID = testID("slot1-9"), testID("slot1-10")
+	left := &ClassAssignment{CourseID: testID("courseC0"), FacultyID: testID("faculty0"), RoomID: testID("roomR0"), DayOfWeek: 1, TimeSlot: slot}
+	from := &Solution{Schedule: map[string]*ClassAssignment{"left": left}}
+
+	tabu := newTabuList(3)
+	tabu.forbidReturn(from, &Move{Removed: []string{"left"}}, 5)
+
+	moveBack := func(assignment *ClassAssignment) *Move {
+		return &Move{Added: map[string]*ClassAssignment{"back": assignment}}
+	}
+	back := *left
+	for iteration, want := range map[int]bool{5: true, 6: true, 8: true, 9: false, 20: false} {
+		if got := tabu.isTabu(moveBack(&back), iteration); got != want {
+			t.Errorf("returning at iteration %d: tabu is %v, want %v", iteration, got, want)
+		}
+	}
+
+	// Only the exact placement that was left is tabu
+	for name, change := range map[string]func(*ClassAssignment){
+		"another room":    func(a *ClassAssignment) { a.RoomID = testID("roomR1") },
+		"another faculty": func(a *ClassAssignment) { a.FacultyID = testID("faculty1") },
+		"another time":    func(a *ClassAssignment) { a.TimeSlot = otherSlot },
+		"another meeting": func(a *ClassAssignment) { a.EventIndex = 1 },
+	} {
+		moved := *left
+		change(&moved)
+		if tabu.isTabu(moveBack(&moved), 6) {
+			t.Errorf("moving to %s is tabu", name)
+		}
+	}
+
+	// Later moves clear the entries that have run out
+	tabu.forbidReturn(&Solution{}, &Move{}, 9)
+	if len(tabu.expires) != 0 {
+		t.Errorf("expired entries are kept: %v", tabu.expires)
+	}
+
+	if tenure := newTabuList(0).tenure; tenure != defaultTabuTenure {
+		t.Errorf("unset tenure is %d, want %d", tenure, defaultTabuTenure)
+	}
+}
+
+func TestTabuAspiration(t *testing.T) {
+	// tabuStep samples its neighbour at random, so each case starts a fresh
+	// engine with the same seed to be offered the same neighbour
+	const seed = 1
+	start := func() (*TimetableEngine, *Solution) {
+		engine := newTestEngine(t, newTestData(), "tabu_search", seed)
+		return engine, engine.randomSolution()
+	}
+
+	engine, current := start()
+	neighbor, move := engine.tabuStep(current, current, newTabuList(5), 0, 1)
+	if move == nil || neighbor.FitnessScore <= current.FitnessScore {
+		t.Fatalf("seed %d does not offer an improving neighbour", seed)
+	}
+
+	// The search has just left the placements the neighbour moves into
+	left := &Solution{Schedule: move.Added}
+	removed := make([]string, 0, len(move.Added))
+	for key := range move.Added {
+		removed = append(removed, key)
+	}
+	tabu := newTabuList(5)
+	tabu.forbidReturn(left, &Move{Removed: removed}, 0)
+	if !tabu.isTabu(move, 1) {
+		t.Fatal("the neighbour's move is not tabu")
+	}
+
+	tests := []struct {
+		name      string
+		best      float64 // fitness of the best solution so far; 0 for current
+		iteration int
+		allowed   bool
+	}{
+		{"tabu move that beats the best", 0, 1, true},
+		{"tabu move that only ties the best", neighbor.FitnessScore, 1, false},
+		{"tabu move below the best", neighbor.FitnessScore + 1, 1, false},
+		{"expired move below the best", neighbor.FitnessScore + 1, 6, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, current := start()
+			best := current
+			if tt.best != 0 {
+				best = &Solution{FitnessScore: tt.best}
+			}
+
+			got, _ := engine.tabuStep(current, best, tabu, tt.iteration, 1)
+			if allowed := got != nil; allowed != tt.allowed {
+				t.Fatalf("move allowed is %v, want %v", allowed, tt.allowed)
+			}
+			if got != nil && got.FitnessScore != neighbor.FitnessScore {
+				t.Fatalf("took a neighbour scoring %v, want %v", got.FitnessScore, neighbor.FitnessScore)
+			}
+		})
+	}
+}
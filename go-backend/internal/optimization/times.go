@@ -0,0 +1,78 @@
+package optimization
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseClock converts a time of day such as "09:00" or "09:00:00", as stored
+// in TIME columns, into minutes after midnight
+func ParseClock(value string) (int, error) {
+	// Parsed by hand: this runs for every overlap check during the search
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+
+	limits := []int{23, 59, 59}
+	fields := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || len(part) == 0 || len(part) > 2 || (i > 0 && len(part) != 2) || n < 0 || n > limits[i] {
+			return 0, fmt.Errorf("invalid time of day %q", value)
+		}
+		fields[i] = n
+	}
+
+	return fields[0]*60 + fields[1], nil
+}
+
+// interval returns the assignment's start and end in minutes after midnight.
+// ok is false when either time cannot be parsed.
+func (a *ClassAssignment) interval() (start, end int, ok bool) {
+	start, err := ParseClock(a.StartTime)
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err = ParseClock(a.EndTime)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// overlapMinutes returns how many minutes two assignments share on the same
+// day, or 0 if they are on different days or their times cannot be parsed
+func (a *ClassAssignment) overlapMinutes(other *ClassAssignment) int {
+	if a.DayOfWeek != other.DayOfWeek {
+		return 0
+	}
+
+	startA, endA, okA := a.interval()
+	startB, endB, okB := other.interval()
+	if !okA || !okB {
+		return 0
+	}
+
+	overlap := min(endA, endB) - max(startA, startB)
+	if overlap < 0 {
+		return 0
+	}
+	return overlap
+}
+
+// overlaps reports whether two assignments share any time on the same day
+func (a *ClassAssignment) overlaps(other *ClassAssignment) bool {
+	return a.overlapMinutes(other) > 0
+}
+
+// clockOrDefault parses a time of day into minutes after midnight, or
+// returns fallback when the value is empty or invalid
+func clockOrDefault(value string, fallback int) int {
+	minutes, err := ParseClock(value)
+	if err != nil {
+		return fallback
+	}
+	return minutes
+}
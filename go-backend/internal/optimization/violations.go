@@ -0,0 +1,148 @@
+package optimization
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/timetable-scheduler/internal/models"
+)
+
+// Conflict types reported by the built-in constraints. They match the
+// conflict_type values written to conflict_logs.
+const (
+	ConflictFacultyDoubleBooking = "FACULTY_DOUBLE_BOOKING"
+	ConflictRoomDoubleBooking    = "ROOM_DOUBLE_BOOKING"
+	ConflictStudentClash         = "STUDENT_GROUP_CLASH"
+	ConflictFacultyOverload      = "FACULTY_OVERLOAD"
+	ConflictRoomCapacity         = "ROOM_CAPACITY_EXCEEDED"
+	ConflictLabRoom              = "LAB_ROOM_REQUIRED"
+	ConflictFacultyUnavailable   = "FACULTY_UNAVAILABLE"
+)
+
+// Violation severities, matching the conflict_logs severity check
+const (
+	SeverityLow      = "LOW"
+	SeverityMedium   = "MEDIUM"
+	SeverityHigh     = "HIGH"
+	SeverityCritical = "CRITICAL"
+)
+
+// Violation describes one breach of a constraint and what it affects
+type Violation struct {
+	Constraint       string      `json:"constraint"`
+	Type             string      `json:"type"`
+	Severity         string      `json:"severity"`
+	Hard             bool        `json:"hard"`
+	Description      string      `json:"description"`
+	Penalty          float64     `json:"penalty"`
+	Keys             []string    `json:"assignment_keys"`
+	FacultyIDs       []uuid.UUID `json:"faculty_ids"`
+	RoomIDs          []uuid.UUID `json:"room_ids"`
+	CourseIDs        []uuid.UUID `json:"course_ids"`
+	StudentGroupIDs  []string    `json:"student_group_ids"`
+	StudentsAffected int         `json:"students_affected,omitempty"`
+}
+
+// ViolationReporter is implemented by constraints that can say which
+// assignments break them, not just whether they are broken
+type ViolationReporter interface {
+	Constraint
+	Violations(solution *Solution) []Violation
+}
+
+// Evaluate scores a schedule the engine did not search for itself, such as
+// one stored with a generation run, so its violations can be listed
+func (e *TimetableEngine) Evaluate(solution *Solution) {
+	e.evaluateSolution(solution)
+}
+
+// Violations lists every constraint breach in the solution. Constraints that
+// do not implement ViolationReporter produce a single summary violation when
+// Evaluate reports them as violated.
+func (e *TimetableEngine) Violations(solution *Solution) []Violation {
+	violations := []Violation{}
+	for _, name := range e.constraintOrder {
+		constraint := e.constraints[name]
+
+		var found []Violation
+		if reporter, ok := constraint.(ViolationReporter); ok {
+			found = reporter.Violations(solution)
+		} else if violated, penalty := constraint.Evaluate(solution); violated {
+			found = []Violation{{Description: constraint.GetDescription(), Penalty: penalty}}
+		}
+
+		for _, violation := range found {
+			violation.Constraint = name
+			violation.Hard = e.weights[name].hard
+			if violation.Type == "" {
+				violation.Type = strings.ToUpper(name)
+			}
+			if violation.Severity == "" {
+				violation.Severity = SeverityLow
+				if violation.Hard {
+					violation.Severity = SeverityHigh
+				}
+			}
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations
+}
+
+// PenaltyBreakdown lists every constraint with the penalty it gives the
+// solution, in the order the constraints were added
+func (e *TimetableEngine) PenaltyBreakdown(solution *Solution) []models.ConstraintPenalty {
+	breakdown := make([]models.ConstraintPenalty, 0, len(e.constraintOrder))
+	for _, name := range e.constraintOrder {
+		result, ok := solution.penalties[name]
+		if !ok {
+			violated, penalty := e.constraints[name].Evaluate(solution)
+			result = constraintResult{violated: violated, penalty: penalty}
+		}
+
+		weight := e.weights[name]
+		breakdown = append(breakdown, models.ConstraintPenalty{
+			Constraint: name,
+			Hard:       weight.hard,
+			Weight:     weight.weight,
+			Violated:   result.violated,
+			Penalty:    result.penalty,
+			Cost:       e.cost(name, result),
+		})
+	}
+	return breakdown
+}
+
+// newViolation fills the affected entities from the assignments behind the
+// given schedule keys
+func newViolation(solution *Solution, conflictType, severity, description string, penalty float64, keys ...string) Violation {
+	violation := Violation{
+		Type:        conflictType,
+		Severity:    severity,
+		Description: description,
+		Penalty:     penalty,
+		Keys:        keys,
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	add := func(ids []uuid.UUID, id uuid.UUID) []uuid.UUID {
+		if id == uuid.Nil || seen[id] {
+			return ids
+		}
+		seen[id] = true
+		return append(ids, id)
+	}
+
+	for _, key := range keys {
+		assignment, ok := solution.Schedule[key]
+		if !ok {
+			continue
+		}
+		violation.FacultyIDs = add(violation.FacultyIDs, assignment.FacultyID)
+		violation.RoomIDs = add(violation.RoomIDs, assignment.RoomID)
+		violation.CourseIDs = add(violation.CourseIDs, assignment.CourseID)
+	}
+
+	return violation
+}
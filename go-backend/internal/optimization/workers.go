@@ -0,0 +1,108 @@
+package optimization
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// runWorkers runs config.Workers independent searches, each with its own
+// random source seeded from the engine seed, and returns the best solution any
+// of them found. Ties go to the lowest numbered worker, so the result does not
+// depend on which worker finishes first.
+func (e *TimetableEngine) runWorkers(ctx context.Context) (*Solution, error) {
+	workers := e.config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	e.mu.Lock()
+	e.bestSolution = &Solution{
+		Schedule:     make(map[string]*ClassAssignment),
+		FitnessScore: math.Inf(-1),
+	}
+	e.mu.Unlock()
+
+	results := make([]*Solution, workers)
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			worker := e.newWorker(i, e.seed+int64(i))
+			result, err := worker.run(ctx)
+			if result != nil {
+				// Incremental scores drift by rounding; verify the result
+				// with a full evaluation on a private copy
+				result = worker.copySolution(result)
+				worker.evaluateSolution(result)
+				e.offer(result)
+			}
+			results[i], errs[i] = result, err
+		}(i)
+	}
+	wg.Wait()
+
+	var best *Solution
+	var firstErr error
+	for i, result := range results {
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		if result != nil && (best == nil || result.FitnessScore > best.FitnessScore) {
+			best = result
+		}
+	}
+
+	if best == nil {
+		return nil, firstErr
+	}
+	return best, nil
+}
+
+// newWorker returns a copy of the engine for one search. The loaded data and
+// constraints are shared read-only; only the random source is the worker's own.
+func (e *TimetableEngine) newWorker(id int, seed int64) *TimetableEngine {
+	return &TimetableEngine{
+		timetableID:     e.timetableID,
+		config:          e.config,
+		courses:         e.courses,
+		faculty:         e.faculty,
+		rooms:           e.rooms,
+		timeSlots:       e.timeSlots,
+		courseIndex:     e.courseIndex,
+		events:          e.events,
+		slotBlocks:      e.slotBlocks,
+		expertise:       e.expertise,
+		courseHours:     e.courseHours,
+		enrollments:     e.enrollments,
+		conflicts:       e.conflicts,
+		constraints:     e.constraints,
+		constraintOrder: e.constraintOrder,
+		weights:         e.weights,
+		seed:            e.seed,
+		progress:        e.progress,
+		startedAt:       e.startedAt,
+		rng:             rand.New(rand.NewSource(seed)),
+		worker:          id,
+		shared:          e,
+	}
+}
+
+// offer records a solution as the overall best if it beats every solution
+// found so far, and returns the overall best
+func (e *TimetableEngine) offer(solution *Solution) *Solution {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.bestSolution == nil || solution.FitnessScore > e.bestSolution.FitnessScore {
+		e.bestSolution = solution
+	}
+	return e.bestSolution
+}